@@ -0,0 +1,74 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceGraalSystemsFirewallRule returns a datasource that can be used to retrieve a firewall rule from the GraalSystems API
+func dataSourceGraalSystemsFirewallRule() *schema.Resource {
+	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsFirewallRule().Schema)
+	dsSchema["firewall_rule_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The ID of the firewall rule",
+	}
+	dsSchema["name"].Optional = true
+
+	return &schema.Resource{
+		ReadContext: dataSourceGraalSystemsFirewallRuleRead,
+		Schema:      dsSchema,
+	}
+}
+
+// dataSourceGraalSystemsFirewallRuleRead reads the firewall rule from the GraalSystems API and returns its attributes
+// The firewall rule can be retrieved by its id or its name
+func dataSourceGraalSystemsFirewallRuleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	// Retrieve the input
+	firewallRuleId := d.Get("firewall_rule_id").(string)
+	name := d.Get("name").(string)
+	if firewallRuleId == "" && name == "" {
+		return diag.FromErr(fmt.Errorf("firewall_rule_id or name must be set"))
+	}
+	if firewallRuleId != "" && name != "" {
+		return diag.FromErr(fmt.Errorf("firewall_rule_id and name cannot be set at the same time"))
+	}
+
+	// Retrieving the firewall rule by its name need to retrieve all the firewall rules and filter them
+	if name != "" {
+		rules, _, err := apiClient.FirewallRuleAPI.FindFirewallRules(context.Background()).XTenant(meta.tenant).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		var matches []sdk.FirewallRule
+		for _, rule := range rules {
+			if strings.TrimSpace(flattenStringPtr(rule.Name)) == strings.TrimSpace(name) {
+				matches = append(matches, rule)
+			}
+		}
+		if len(matches) == 0 {
+			return diag.FromErr(fmt.Errorf("no firewall rule exists with the name %s", name))
+		}
+		if len(matches) > 1 {
+			return diag.FromErr(fmt.Errorf("%d firewall rules exist with the same name %s. You can filter them by their id", len(matches), name))
+		}
+		firewallRuleId = *matches[0].Id
+	}
+
+	d.SetId(firewallRuleId)
+	_ = d.Set("firewall_rule_id", firewallRuleId)
+
+	diagnostics := resourceGraalSystemsFirewallRuleRead(ctx, d, meta)
+	if diagnostics == nil && d.Id() == "" {
+		return diag.FromErr(fmt.Errorf("no firewall rule exists with the id %s", firewallRuleId))
+	}
+	return diagnostics
+}
@@ -0,0 +1,40 @@
+package graalsystems
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceGraalSystemsGroupMembership returns a datasource that can be used to retrieve the members of a group from the GraalSystems API
+func dataSourceGraalSystemsGroupMembership() *schema.Resource {
+	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsGroupMembership().Schema)
+	// Every member of the group is read
+	delete(dsSchema, "authoritative")
+	fixDatasourceSchemaFlags(dsSchema, true, "group_id")
+
+	return &schema.Resource{
+		ReadContext: dataSourceGraalSystemsGroupMembershipRead,
+		Schema:      dsSchema,
+	}
+}
+
+// dataSourceGraalSystemsGroupMembershipRead reads the members of the group
+func dataSourceGraalSystemsGroupMembershipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	groupId := d.Get("group_id").(string)
+	members, _, err := apiClient.GroupAPI.FindMembersByGroupId(context.Background(), groupId).XTenant(meta.tenant).Execute()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(groupId)
+	for memberType, attribute := range groupMemberAttributes {
+		_ = d.Set(attribute, groupMemberIds(members, memberType))
+	}
+
+	return nil
+}
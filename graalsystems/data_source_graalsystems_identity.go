@@ -11,6 +11,12 @@ import (
 
 func dataSourceGraalSystemsIdentity() *schema.Resource {
 	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsIdentity().Schema)
+	// The secrets of the credentials are never returned by the API
+	for kind, fields := range credentialSecretFields {
+		for _, field := range fields {
+			delete(dsSchema[kind].Elem.(*schema.Resource).Schema, field)
+		}
+	}
 
 	dsSchema["identity_id"] = &schema.Schema{
 		Type:        schema.TypeString,
@@ -0,0 +1,110 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceGraalSystemsInfrastructure returns a datasource that can be used to retrieve an infrastructure from the GraalSystems API
+func dataSourceGraalSystemsInfrastructure() *schema.Resource {
+	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsInfrastructure().Schema)
+
+	dsSchema["infrastructure_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The ID of the infrastructure",
+	}
+	dsSchema["name"].Optional = true
+	dsSchema["provider_name"].Optional = true
+	dsSchema["region"].Optional = true
+
+	return &schema.Resource{
+		ReadContext: dataSourceGraalSystemsInfrastructureRead,
+		Schema:      dsSchema,
+	}
+}
+
+// dataSourceGraalSystemsInfrastructureRead reads the infrastructure from the GraalSystems API and returns its attributes
+// The infrastructure can be retrieved by its id, or by any combination of its name, provider and region matching a single infrastructure
+func dataSourceGraalSystemsInfrastructureRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	// Retrieve the input
+	infrastructureId := d.Get("infrastructure_id").(string)
+	filters := map[string]string{
+		"name":          d.Get("name").(string),
+		"provider_name": d.Get("provider_name").(string),
+		"region":        d.Get("region").(string),
+	}
+	filtered := filters["name"] != "" || filters["provider_name"] != "" || filters["region"] != ""
+	if infrastructureId == "" && !filtered {
+		return diag.FromErr(fmt.Errorf("infrastructure_id or one of name, provider_name and region must be set"))
+	}
+	if infrastructureId != "" && filtered {
+		return diag.FromErr(fmt.Errorf("infrastructure_id cannot be set at the same time as name, provider_name or region"))
+	}
+
+	// Retrieving the infrastructure by its attributes need to retrieve all the infrastructures and filter them
+	if filtered {
+		infrastructures, _, err := apiClient.InfrastructureAPI.FindInfrastructures(context.Background()).XTenant(meta.tenant).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		matches := filterInfrastructures(infrastructures, filters)
+		if len(matches) == 0 {
+			return diag.FromErr(fmt.Errorf("no infrastructure matches %s", describeInfrastructureFilters(filters)))
+		}
+		if len(matches) > 1 {
+			return diag.FromErr(fmt.Errorf("%d infrastructures match %s. You can filter them by their id", len(matches), describeInfrastructureFilters(filters)))
+		}
+		infrastructureId = *matches[0].Id
+	}
+
+	d.SetId(infrastructureId)
+	_ = d.Set("infrastructure_id", infrastructureId)
+
+	diagnostics := resourceGraalSystemsInfrastructureRead(ctx, d, meta)
+	if diagnostics == nil && d.Id() == "" {
+		return diag.FromErr(fmt.Errorf("no infrastructure exists with the id %s", infrastructureId))
+	}
+	return diagnostics
+}
+
+// filterInfrastructures returns the infrastructures matching every non-empty filter
+func filterInfrastructures(infrastructures []sdk.Infrastructure, filters map[string]string) []sdk.Infrastructure {
+	var matches []sdk.Infrastructure
+	for _, infrastructure := range infrastructures {
+		values := map[string]string{
+			"name":          flattenStringPtr(infrastructure.Name),
+			"provider_name": flattenStringPtr(infrastructure.Provider),
+			"region":        flattenStringPtr(infrastructure.Region),
+		}
+		matching := true
+		for key, filter := range filters {
+			if filter != "" && strings.TrimSpace(values[key]) != strings.TrimSpace(filter) {
+				matching = false
+			}
+		}
+		if matching {
+			matches = append(matches, infrastructure)
+		}
+	}
+	return matches
+}
+
+// describeInfrastructureFilters formats the non-empty filters for the error messages
+func describeInfrastructureFilters(filters map[string]string) string {
+	var description []string
+	for _, key := range []string{"name", "provider_name", "region"} {
+		if filters[key] != "" {
+			description = append(description, fmt.Sprintf("%s %q", key, filters[key]))
+		}
+	}
+	return strings.Join(description, ", ")
+}
@@ -0,0 +1,33 @@
+package graalsystems
+
+import (
+	"testing"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterInfrastructures(t *testing.T) {
+	infrastructure := func(id string, name string, provider string, region string) sdk.Infrastructure {
+		return sdk.Infrastructure{Id: &id, Name: &name, Provider: &provider, Region: &region}
+	}
+	infrastructures := []sdk.Infrastructure{
+		infrastructure("1", "production", "aws", "eu-west-1"),
+		infrastructure("2", "production", "aws", "us-east-1"),
+		infrastructure("3", "sandbox", "gcp", "europe-west1"),
+	}
+	ids := func(matches []sdk.Infrastructure) []string {
+		var result []string
+		for _, match := range matches {
+			result = append(result, *match.Id)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"1", "2"}, ids(filterInfrastructures(infrastructures, map[string]string{"name": "production"})))
+	assert.Equal(t, []string{"2"}, ids(filterInfrastructures(infrastructures, map[string]string{"name": "production", "region": "us-east-1"})))
+	assert.Equal(t, []string{"3"}, ids(filterInfrastructures(infrastructures, map[string]string{"provider_name": "gcp", "region": ""})))
+	assert.Empty(t, filterInfrastructures(infrastructures, map[string]string{"name": "sandbox", "provider_name": "aws"}))
+
+	assert.Equal(t, `name "production", region "us-east-1"`, describeInfrastructureFilters(map[string]string{"name": "production", "provider_name": "", "region": "us-east-1"}))
+}
@@ -0,0 +1,180 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceGraalSystemsInstanceTypes returns a datasource listing the instance types available for a project or an infrastructure
+func dataSourceGraalSystemsInstanceTypes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceGraalSystemsInstanceTypesRead,
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The id of the project to list the instance types of",
+				ExactlyOneOf: []string{"project_id", "infrastructure_id"},
+			},
+			"infrastructure_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The id of the infrastructure to list the instance types of",
+				ExactlyOneOf: []string{"project_id", "infrastructure_id"},
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The names of the available instance types",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"instance_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The available instance types",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the instance type, to use as `instance_type` of the jobs and the workspaces",
+						},
+						"cpu": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The number of vCPUs of the instance type",
+						},
+						"memory": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The memory of the instance type, in GiB",
+						},
+						"gpu": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of GPUs of the instance type",
+						},
+						"gpu_model": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The model of the GPUs of the instance type",
+						},
+						"price": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The hourly price of the instance type",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceGraalSystemsInstanceTypesRead lists the instance types available for the project or the infrastructure
+func dataSourceGraalSystemsInstanceTypesRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	projectId := d.Get("project_id").(string)
+	infrastructureId := d.Get("infrastructure_id").(string)
+	instanceTypes, err := findInstanceTypes(meta, projectId, infrastructureId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var names []string
+	var flattened []map[string]interface{}
+	for _, instanceType := range instanceTypes {
+		names = append(names, flattenStringPtr(instanceType.Name))
+		flattened = append(flattened, map[string]interface{}{
+			"name":      flattenStringPtr(instanceType.Name),
+			"cpu":       flattenFloat32Ptr(instanceType.Cpu),
+			"memory":    flattenFloat32Ptr(instanceType.Memory),
+			"gpu":       flattenInt32Ptr(instanceType.Gpu),
+			"gpu_model": flattenStringPtr(instanceType.GpuModel),
+			"price":     flattenFloat64Ptr(instanceType.Price),
+		})
+	}
+
+	if infrastructureId != "" {
+		d.SetId(infrastructureId)
+	} else {
+		d.SetId(projectId)
+	}
+	_ = d.Set("names", flattenSliceString(names))
+	_ = d.Set("instance_types", flattened)
+
+	return nil
+}
+
+// findInstanceTypes returns the instance types available on the infrastructure, or for the project if no infrastructure is given
+func findInstanceTypes(meta *Meta, projectId string, infrastructureId string) ([]sdk.InstanceType, error) {
+	apiClient := meta.apiClient
+	if infrastructureId != "" {
+		instanceTypes, _, err := apiClient.InfrastructureAPI.FindInstanceTypesByInfrastructureId(context.Background(), infrastructureId).XTenant(meta.tenant).Execute()
+		return instanceTypes, err
+	}
+	instanceTypes, _, err := apiClient.ProjectAPI.FindInstanceTypesByProjectId(context.Background(), projectId).XTenant(meta.tenant).Execute()
+	return instanceTypes, err
+}
+
+// validateInstanceType checks that the instance type is part of the available ones
+func validateInstanceType(instanceType string, instanceTypes []sdk.InstanceType, target string) error {
+	var names []string
+	for _, available := range instanceTypes {
+		if flattenStringPtr(available.Name) == instanceType {
+			return nil
+		}
+		names = append(names, flattenStringPtr(available.Name))
+	}
+	return fmt.Errorf("instance type %q is not available on %s. Available instance types: %s", instanceType, target, strings.Join(names, ", "))
+}
+
+// customizeDiffInstanceType checks at plan time that the instance type exists on the target infrastructure, or for the target project if no infrastructure is given.
+// The check only runs when one of the attributes changes and all of them are known.
+func customizeDiffInstanceType(instanceTypeKey string, projectIdKey string, infrastructureIdKey string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, m interface{}) error {
+		keys := []string{instanceTypeKey}
+		if projectIdKey != "" {
+			keys = append(keys, projectIdKey)
+		}
+		if infrastructureIdKey != "" {
+			keys = append(keys, infrastructureIdKey)
+		}
+		changed := false
+		for _, key := range keys {
+			if !d.NewValueKnown(key) {
+				return nil
+			}
+			changed = changed || d.HasChange(key)
+		}
+		instanceType, _ := d.Get(instanceTypeKey).(string)
+		if !changed || instanceType == "" || m == nil {
+			return nil
+		}
+
+		var projectId, infrastructureId, target string
+		if infrastructureIdKey != "" {
+			infrastructureId, _ = d.Get(infrastructureIdKey).(string)
+			target = fmt.Sprintf("infrastructure %s", infrastructureId)
+		}
+		if infrastructureId == "" && projectIdKey != "" {
+			projectId, _ = d.Get(projectIdKey).(string)
+			target = fmt.Sprintf("project %s", projectId)
+		}
+		if infrastructureId == "" && projectId == "" {
+			return nil
+		}
+
+		instanceTypes, err := findInstanceTypes(m.(*Meta), projectId, infrastructureId)
+		if err != nil {
+			return fmt.Errorf("cannot list the instance types of %s: %s", target, err)
+		}
+		return validateInstanceType(instanceType, instanceTypes, target)
+	}
+}
@@ -0,0 +1,18 @@
+package graalsystems
+
+import (
+	"testing"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateInstanceType(t *testing.T) {
+	small, large := "Standard_Development_D0_v1", "Standard_General_G1_v1"
+	instanceTypes := []sdk.InstanceType{{Name: &small}, {Name: &large}}
+
+	assert.Nil(t, validateInstanceType(large, instanceTypes, "project p"))
+
+	err := validateInstanceType("Standard_General_G1_v2", instanceTypes, "project p")
+	assert.EqualError(t, err, `instance type "Standard_General_G1_v2" is not available on project p. Available instance types: Standard_Development_D0_v1, Standard_General_G1_v1`)
+}
@@ -0,0 +1,78 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceGraalSystemsLibrary returns a datasource that can be used to retrieve an uploaded library from the GraalSystems API
+func dataSourceGraalSystemsLibrary() *schema.Resource {
+	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsLibrary().Schema)
+	// The source file and its hash are only known by the resource that uploaded the library
+	delete(dsSchema, "source")
+	delete(dsSchema, "content_hash")
+
+	dsSchema["library_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The ID of the library",
+	}
+	dsSchema["name"].Optional = true
+
+	return &schema.Resource{
+		ReadContext: dataSourceGraalSystemsLibraryRead,
+		Schema:      dsSchema,
+	}
+}
+
+// dataSourceGraalSystemsLibraryRead reads the library from the GraalSystems API and returns its attributes
+// The library can be retrieved by its id or its name
+func dataSourceGraalSystemsLibraryRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	// Retrieve the input
+	libraryId := d.Get("library_id").(string)
+	name := d.Get("name").(string)
+	if libraryId == "" && name == "" {
+		return diag.FromErr(fmt.Errorf("library_id or name must be set"))
+	}
+	if libraryId != "" && name != "" {
+		return diag.FromErr(fmt.Errorf("library_id and name cannot be set at the same time"))
+	}
+
+	// Retrieving the library by its name need to retrieve all the libraries and filter them
+	if name != "" {
+		libraries, _, err := apiClient.LibraryAPI.FindLibraries(context.Background()).XTenant(meta.tenant).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		var matches []sdk.LibraryMetadata
+		for _, library := range libraries {
+			if strings.TrimSpace(flattenStringPtr(library.Name)) == strings.TrimSpace(name) {
+				matches = append(matches, library)
+			}
+		}
+		if len(matches) == 0 {
+			return diag.FromErr(fmt.Errorf("no library exists with the name %s", name))
+		}
+		if len(matches) > 1 {
+			return diag.FromErr(fmt.Errorf("%d libraries exist with the same name %s. You can filter them by their id", len(matches), name))
+		}
+		libraryId = *matches[0].Id
+	}
+
+	d.SetId(libraryId)
+	_ = d.Set("library_id", libraryId)
+
+	diagnostics := resourceGraalSystemsLibraryRead(ctx, d, meta)
+	if diagnostics == nil && d.Id() == "" {
+		return diag.FromErr(fmt.Errorf("no library exists with the id %s", libraryId))
+	}
+	return diagnostics
+}
@@ -0,0 +1,77 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceGraalSystemsSecret returns a datasource that can be used to retrieve a secret from the GraalSystems API
+func dataSourceGraalSystemsSecret() *schema.Resource {
+	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsSecret().Schema)
+	// The value of a secret is write-only
+	delete(dsSchema, "value")
+
+	dsSchema["secret_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The ID of the secret",
+	}
+	dsSchema["name"].Optional = true
+
+	return &schema.Resource{
+		ReadContext: dataSourceGraalSystemsSecretRead,
+		Schema:      dsSchema,
+	}
+}
+
+// dataSourceGraalSystemsSecretRead reads the secret from the GraalSystems API and returns its attributes
+// The secret can be retrieved by its id or its name
+func dataSourceGraalSystemsSecretRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	// Retrieve the input
+	secretId := d.Get("secret_id").(string)
+	name := d.Get("name").(string)
+	if secretId == "" && name == "" {
+		return diag.FromErr(fmt.Errorf("secret_id or name must be set"))
+	}
+	if secretId != "" && name != "" {
+		return diag.FromErr(fmt.Errorf("secret_id and name cannot be set at the same time"))
+	}
+
+	// Retrieving the secret by its name need to retrieve all the secrets and filter them
+	if name != "" {
+		secrets, _, err := apiClient.SecretAPI.FindSecrets(context.Background()).XTenant(meta.tenant).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		var matches []sdk.Secret
+		for _, secret := range secrets {
+			if strings.TrimSpace(flattenStringPtr(secret.Name)) == strings.TrimSpace(name) {
+				matches = append(matches, secret)
+			}
+		}
+		if len(matches) == 0 {
+			return diag.FromErr(fmt.Errorf("no secret exists with the name %s", name))
+		}
+		if len(matches) > 1 {
+			return diag.FromErr(fmt.Errorf("%d secrets exist with the same name %s. You can filter them by their id", len(matches), name))
+		}
+		secretId = *matches[0].Id
+	}
+
+	d.SetId(secretId)
+	_ = d.Set("secret_id", secretId)
+
+	diagnostics := resourceGraalSystemsSecretRead(ctx, d, meta)
+	if diagnostics == nil && d.Id() == "" {
+		return diag.FromErr(fmt.Errorf("no secret exists with the id %s", secretId))
+	}
+	return diagnostics
+}
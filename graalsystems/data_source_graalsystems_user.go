@@ -3,6 +3,7 @@ package graalsystems
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	sdk "github.com/graalsystems/sdk/go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -11,12 +12,16 @@ import (
 
 func dataSourceGraalSystemsUser() *schema.Resource {
 	dsSchema := datasourceSchemaFromResourceSchema(resourceGraalSystemsUser().Schema)
+	// The invitation is only sent by the resource creating the user
+	delete(dsSchema, "send_invitation")
 
 	dsSchema["user_id"] = &schema.Schema{
 		Type:        schema.TypeString,
 		Optional:    true,
 		Description: "The ID of the user",
 	}
+	dsSchema["username"].Optional = true
+	dsSchema["email"].Optional = true
 
 	return &schema.Resource{
 		ReadContext: dataSourceGraalSystemsUserRead,
@@ -24,34 +29,54 @@ func dataSourceGraalSystemsUser() *schema.Resource {
 	}
 }
 
+// dataSourceGraalSystemsUserRead reads the user from the GraalSystems API and returns its attributes
+// The user can be retrieved by its id, its username or its email address
 func dataSourceGraalSystemsUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
-	var user sdk.User
-	userId, ok := d.Get("user_id").(string)
-	if ok {
-		p, _, err := apiClient.UserAPI.FindUserById(context.Background(), userId).XTenant(meta.tenant).Execute()
-		if err != nil {
-			return diag.FromErr(err)
+	// Retrieve the input
+	userId := d.Get("user_id").(string)
+	username := d.Get("username").(string)
+	email := d.Get("email").(string)
+	inputs := 0
+	for _, input := range []string{userId, username, email} {
+		if input != "" {
+			inputs++
 		}
-		user = *p
-	} else {
+	}
+	if inputs != 1 {
+		return diag.FromErr(fmt.Errorf("exactly one of user_id, username and email must be set"))
+	}
+
+	// Retrieving the user by its username or email need to retrieve all the users and filter them
+	if userId == "" {
 		users, _, err := apiClient.UserAPI.FindUsers(context.Background()).XTenant(meta.tenant).Execute()
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		if len(users) == 0 {
-			return diag.FromErr(fmt.Errorf("no user found with the name %s", d.Get("name")))
+		var matches []sdk.User
+		for _, user := range users {
+			if (username != "" && flattenStringPtr(user.Username) == strings.TrimSpace(username)) ||
+				(email != "" && strings.EqualFold(flattenStringPtr(user.Email), strings.TrimSpace(email))) {
+				matches = append(matches, user)
+			}
+		}
+		if len(matches) == 0 {
+			return diag.FromErr(fmt.Errorf("no user exists with the username or email %s%s", username, email))
 		}
-		if len(users) > 1 {
-			return diag.FromErr(fmt.Errorf("%d users found with the same name %s", len(users), d.Get("name")))
+		if len(matches) > 1 {
+			return diag.FromErr(fmt.Errorf("%d users exist with the same username or email %s%s. You can filter them by their id", len(matches), username, email))
 		}
-		user = users[0]
+		userId = *matches[0].Id
 	}
 
-	d.SetId(*user.Id)
-	_ = d.Set("user_id", user.Id)
+	d.SetId(userId)
+	_ = d.Set("user_id", userId)
 
-	return resourceGraalSystemsUserRead(ctx, d, meta)
+	diagnostics := resourceGraalSystemsUserRead(ctx, d, meta)
+	if diagnostics == nil && d.Id() == "" {
+		return diag.FromErr(fmt.Errorf("no user exists with the id %s", userId))
+	}
+	return diagnostics
 }
@@ -1,12 +1,15 @@
 package graalsystems
 
 import (
+	"encoding/json"
 	"fmt"
 	sdk "github.com/graalsystems/sdk/go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"net/http"
+	"reflect"
 	"slices"
 	"strconv"
+	"strings"
 )
 import "errors"
 
@@ -65,129 +68,289 @@ func toStringMap(input map[string]interface{}) map[string]string {
 	return output
 }
 
-func validatePatchOperation(operation *string) error {
-	operations := []string{"add", "replace", "remove", "move", "copy", "test"}
-	if slices.Contains(operations, *operation) {
+// expandStringPtr returns a pointer to data, or nil if data is empty
+func expandStringPtr(data interface{}) *string {
+	if data == nil || data == "" {
 		return nil
-	} else {
-		return fmt.Errorf("invalid patch operation: %s", *operation)
 	}
+	str := data.(string)
+	return &str
 }
 
-// patchString creates a patch from a string input
-func patchString(d *schema.ResourceData, patchElement string) (*sdk.Patch, error) {
-	path := "/" + patchElement
+// expandInt32Ptr returns a pointer to data converted to int32, or nil if data is 0
+func expandInt32Ptr(data interface{}) *int32 {
+	if data == nil || data == 0 {
+		return nil
+	}
+	i := int32(data.(int))
+	return &i
+}
 
-	old, val := d.GetChange(patchElement)
+// flattenStringPtr returns the value of s, or an empty string if s is nil
+func flattenStringPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
 
-	value := val.(string)
-	var op string
-	if old == nil {
-		op = "add"
-	} else if val == nil {
-		op = "remove"
-	} else {
-		op = "replace"
+// flattenInt32Ptr returns the value of i, or 0 if i is nil
+func flattenInt32Ptr(i *int32) int {
+	if i == nil {
+		return 0
 	}
+	return int(*i)
+}
 
-	if err := validatePatchOperation(&op); err != nil {
-		return nil, err
-	} else {
-		return &sdk.Patch{Op: &op, Path: &path, Value: &value}, nil
+// flattenFloat32Ptr returns the value of f, or 0 if f is nil
+func flattenFloat32Ptr(f *float32) float64 {
+	if f == nil {
+		return 0
 	}
+	return float64(*f)
 }
 
-// patchMap creates a patch from a map input
-func patchMap(d *schema.ResourceData, patchElement string) ([]sdk.Patch, error) {
-	path := "/" + patchElement + "/"
-	old, val := d.GetChange(patchElement)
-	oldValues := toStringMap(old.(map[string]interface{}))
-	values := val.(map[string]interface{})
-	var patches []sdk.Patch
+// flattenFloat64Ptr returns the value of f, or 0 if f is nil
+func flattenFloat64Ptr(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
 
-	for k, v := range toStringMap(values) {
-		var op string
-		if _, ok := oldValues[k]; !ok {
-			op = "add"
-		} else {
-			op = "replace"
-		}
-		path += k
-		value := v
-		patches = append(patches, sdk.Patch{Op: &op, Path: &path, Value: &value})
-	}
-
-	path = "/" + patchElement + "/"
-	for k, _ := range oldValues {
-		if _, ok := values[k]; !ok {
-			var op string
-			op = "remove"
-			path += k
-			patches = append(patches, sdk.Patch{Op: &op, Path: &path})
-		}
+// flattenSliceString converts a list of strings to a list of interfaces, as returned by the terraform schema
+func flattenSliceString(s []string) []interface{} {
+	res := make([]interface{}, 0, len(s))
+	for _, str := range s {
+		res = append(res, str)
 	}
-	return patches, nil
+	return res
 }
 
-func patchList(d *schema.ResourceData, patchElement string) ([]sdk.Patch, error) {
-	/*path := "/" + patchElement + "/"
-	old, val := d.GetChange(patchElement)
-	oldList := old.([]interface{})
-	valList := val.([]interface{})
-	var patches []sdk.Patch
-	for i, v := range valList {
-		switch v.(type) {
-		case string:
-			if v.(string) != oldList[i].(string) {
-				strPatches, err := patchString(d, patchElement, i)
-				if err != nil {
-					return nil, err
-				}
-				patches = append(patches, *strPatches)
-			}
-		case map[string]interface{}:
-			mapPatches, err := patchMap(d, patchElement, i)
-			if err != nil {
-				return nil, err
-			}
-			for _, patch := range mapPatches {
-				patches = append(patches, patch)
-			}
+// flattenStringMapPtr returns the value of m, or an empty map if m is nil
+func flattenStringMapPtr(m *map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return *m
+}
+
+// intersectLists returns the elements of a that are also in b
+func intersectLists[T comparable](a []T, b []T) []T {
+	var result []T
+	for _, elem := range a {
+		if slices.Contains(b, elem) {
+			result = append(result, elem)
 		}
 	}
+	return result
+}
 
-	return patches, nil*/
-	return nil, fmt.Errorf("not implemented")
+// managedElements returns the current elements of an object managed by a resource, e.g. the members of a group or the permissions of a project.
+// An authoritative resource manages every current element, a non-authoritative one only the managed elements, ignoring the ones managed elsewhere.
+func managedElements[T comparable](current []T, managed []T, authoritative bool) []T {
+	if authoritative {
+		return current
+	}
+	return intersectLists(current, managed)
 }
 
-// patchFromResourceData creates a patch from a resource data
-func patchFromResourceData(d *schema.ResourceData, patchElement string) []sdk.Patch {
-	switch d.Get(patchElement).(type) {
-	case string:
-		if patch, err := patchString(d, patchElement); err != nil {
-			fmt.Println(err)
-			return nil
-		} else {
-			return []sdk.Patch{*patch}
+// reconcileChanges returns the wanted elements missing from the current ones, and the managed elements to remove as they are not wanted anymore.
+// The managed elements already removed outside of Terraform are not removed again.
+func reconcileChanges[T comparable](current []T, managed []T, wanted []T, authoritative bool) ([]T, []T) {
+	var toAdd, toRemove []T
+	for _, elem := range wanted {
+		if !slices.Contains(current, elem) {
+			toAdd = append(toAdd, elem)
 		}
+	}
+	for _, elem := range managedElements(current, managed, authoritative) {
+		if !slices.Contains(wanted, elem) {
+			toRemove = append(toRemove, elem)
+		}
+	}
+	return toAdd, toRemove
+}
+
+const (
+	patchOpAdd     = "add"
+	patchOpRemove  = "remove"
+	patchOpReplace = "replace"
+)
+
+// patchDiscriminatorKey is the property holding the concrete type of the polymorphic API objects (options, schedules, libraries, tasks...)
+const patchDiscriminatorKey = "type"
+
+// isEmptyValue returns true if v is nil, an empty string, an empty map or an empty list.
+// Terraform reports unset strings, maps and lists as empty values, so they are considered absent from the API object.
+// Numbers and booleans are always present, since 0 and false are meaningful values, e.g. a job never retried.
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// isNullInConfig returns true if the top level attribute is not set in the configuration.
+// Terraform reports an unset number or boolean as 0 or false, so the configuration tells whether it was removed.
+func isNullInConfig(d *schema.ResourceData, attribute string) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(attribute) {
+		return false
+	}
+	value := config.GetAttr(attribute)
+	return value.IsKnown() && value.IsNull()
+}
+
+// escapePathSegment escapes a reference token of a JSON Pointer, as defined in RFC 6901
+func escapePathSegment(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+// toJSONValue converts an API object to its generic JSON representation (maps, lists, strings, numbers and booleans)
+// so that it can be compared and patched property by property
+func toJSONValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("patch value marshall error: %s", err)
+	}
+	var jsonValue interface{}
+	if err := json.Unmarshal(bytes, &jsonValue); err != nil {
+		return nil, fmt.Errorf("patch value unmarshall error: %s", err)
+	}
+	return jsonValue, nil
+}
+
+// newPatch creates a single patch operation
+func newPatch(op string, path string, value interface{}) sdk.Patch {
+	if op == patchOpRemove {
+		return sdk.Patch{Op: &op, Path: &path}
+	}
+	return sdk.Patch{Op: &op, Path: &path, Value: value}
+}
+
+// diffPatches returns the RFC 6902 patches transforming old into val, both located at path.
+// Empty values are considered absent, so going from or to an empty value adds or removes path.
+// A changed number or boolean is replaced, including from or to 0 or false.
+func diffPatches(path string, old interface{}, val interface{}) []sdk.Patch {
+	if isEmptyValue(old) && isEmptyValue(val) {
+		return nil
+	}
+	if isEmptyValue(old) {
+		return []sdk.Patch{newPatch(patchOpAdd, path, val)}
+	}
+	if isEmptyValue(val) {
+		return []sdk.Patch{newPatch(patchOpRemove, path, nil)}
+	}
+	return diffValues(path, old, val)
+}
+
+// diffValues returns the patches transforming old into val, both being present at path
+func diffValues(path string, old interface{}, val interface{}) []sdk.Patch {
+	switch oldValue := old.(type) {
 	case map[string]interface{}:
-		if patches, err := patchMap(d, patchElement); err != nil {
-			fmt.Println(err)
-			return nil
-		} else {
-			return patches
+		if value, ok := val.(map[string]interface{}); ok {
+			// An object changing of concrete type is replaced as a whole
+			if !reflect.DeepEqual(oldValue[patchDiscriminatorKey], value[patchDiscriminatorKey]) {
+				return []sdk.Patch{newPatch(patchOpReplace, path, val)}
+			}
+			return diffMaps(path, oldValue, value)
 		}
 	case []interface{}:
-		if patches, err := patchList(d, patchElement); err != nil {
-			fmt.Println(err)
-			return nil
-		} else {
-			return patches
+		if value, ok := val.([]interface{}); ok {
+			return diffLists(path, oldValue, value)
 		}
-	default:
-		fmt.Println("Could not find the proper type for the patch")
 	}
-	return nil
+	if reflect.DeepEqual(old, val) {
+		return nil
+	}
+	return []sdk.Patch{newPatch(patchOpReplace, path, val)}
+}
+
+// diffMaps returns the patches transforming the old map into val, key by key
+func diffMaps(path string, old map[string]interface{}, val map[string]interface{}) []sdk.Patch {
+	keys := make([]string, 0, len(old)+len(val))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range val {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	// Sort the keys so that the patches are deterministic
+	slices.Sort(keys)
+
+	var patches []sdk.Patch
+	for _, k := range keys {
+		patches = append(patches, diffPatches(path+"/"+escapePathSegment(k), old[k], val[k])...)
+	}
+	return patches
+}
+
+// diffLists returns the patches transforming the old list into val.
+// Common elements are patched in place, then the extra elements are either removed from the end or appended.
+func diffLists(path string, old []interface{}, val []interface{}) []sdk.Patch {
+	var patches []sdk.Patch
+	common := min(len(old), len(val))
+	for i := 0; i < common; i++ {
+		patches = append(patches, diffValues(path+"/"+strconv.Itoa(i), old[i], val[i])...)
+	}
+	// Remove from the end so that the indexes of the remaining elements do not move
+	for i := len(old) - 1; i >= common; i-- {
+		patches = append(patches, newPatch(patchOpRemove, path+"/"+strconv.Itoa(i), nil))
+	}
+	for i := common; i < len(val); i++ {
+		patches = append(patches, newPatch(patchOpAdd, path+"/"+strconv.Itoa(i), val[i]))
+	}
+	return patches
+}
+
+// patchFromResourceData creates the patches of the change of the attribute patchElement, located at the same path in the API object
+// An attribute removed from the configuration, and without default value, is removed from the API object whatever its type.
+func patchFromResourceData(d *schema.ResourceData, patchElement string) []sdk.Patch {
+	old, val := d.GetChange(patchElement)
+	if val != nil && reflect.ValueOf(val).IsZero() && isNullInConfig(d, patchElement) {
+		val = nil
+	}
+	return diffPatches("/"+escapePathSegment(patchElement), old, val)
+}
+
+// patchesFromResourceData creates the patches of every changed attribute, each located at the same path in the API object
+func patchesFromResourceData(d *schema.ResourceData, patchElements ...string) []sdk.Patch {
+	var patches []sdk.Patch
+	for _, patchElement := range patchElements {
+		if d.HasChange(patchElement) {
+			patches = append(patches, patchFromResourceData(d, patchElement)...)
+		}
+	}
+	return patches
+}
+
+// patchFromConvertedResourceData creates the patches of the change of the attribute patchElement, located at path in the API object.
+// Both the old and new values are converted to their API representation before being compared.
+func patchFromConvertedResourceData(d *schema.ResourceData, patchElement string, path string, convert func(interface{}) interface{}) ([]sdk.Patch, error) {
+	old, val := d.GetChange(patchElement)
+	oldValue, err := toJSONValue(convert(old))
+	if err != nil {
+		return nil, err
+	}
+	value, err := toJSONValue(convert(val))
+	if err != nil {
+		return nil, err
+	}
+	return diffPatches(path, oldValue, value), nil
 }
 
 //func is412Error(err error) bool {
@@ -340,13 +503,6 @@ func patchFromResourceData(d *schema.ResourceData, patchElement string) []sdk.Pa
 //	return ip.String()
 //}
 //
-//func flattenStringPtr(s *string) interface{} {
-//	if s == nil {
-//		return ""
-//	}
-//	return *s
-//}
-//
 //func flattenSliceStringPtr(s []*string) interface{} {
 //	res := make([]interface{}, 0, len(s))
 //	for _, strPtr := range s {
@@ -355,14 +511,6 @@ func patchFromResourceData(d *schema.ResourceData, patchElement string) []sdk.Pa
 //	return res
 //}
 //
-//func flattenSliceString(s []string) interface{} {
-//	res := make([]interface{}, 0, len(s))
-//	for _, strPtr := range s {
-//		res = append(res, strPtr)
-//	}
-//	return res
-//}
-//
 //func flattenBoolPtr(b *bool) interface{} {
 //	if b == nil {
 //		return nil
@@ -370,13 +518,6 @@ func patchFromResourceData(d *schema.ResourceData, patchElement string) []sdk.Pa
 //	return *b
 //}
 //
-//func expandStringPtr(data interface{}) *string {
-//	if data == nil || data == "" {
-//		return nil
-//	}
-//	return StringPtr(data.(string))
-//}
-//
 //func expandUpdatedStringPtr(data interface{}) *string {
 //	str := ""
 //	if data != nil {
@@ -392,13 +533,6 @@ func patchFromResourceData(d *schema.ResourceData, patchElement string) []sdk.Pa
 //	return scw.BoolPtr(data.(bool))
 //}
 //
-//func flattenInt32Ptr(i *int32) interface{} {
-//	if i == nil {
-//		return 0
-//	}
-//	return *i
-//}
-//
 //func expandInt32Ptr(data interface{}) *int32 {
 //	if data == nil || data == "" {
 //		return nil
@@ -0,0 +1,171 @@
+package graalsystems
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// patchSummary flattens patches to "op path" strings so that they are easy to compare
+func patchSummary(patches []sdk.Patch) []string {
+	var summary []string
+	for _, patch := range patches {
+		summary = append(summary, *patch.Op+" "+*patch.Path)
+	}
+	return summary
+}
+
+func TestReconcileChanges(t *testing.T) {
+	// Non-authoritative: only the managed elements present in the object can be removed
+	toAdd, toRemove := reconcileChanges([]string{"alice", "bob", "carol"}, []string{"bob", "erin"}, []string{"alice", "dave"}, false)
+	assert.Equal(t, []string{"dave"}, toAdd)
+	assert.Equal(t, []string{"bob"}, toRemove)
+
+	// Authoritative: every current element is managed
+	toAdd, toRemove = reconcileChanges([]string{"alice", "bob", "carol"}, nil, []string{"alice"}, true)
+	assert.Nil(t, toAdd)
+	assert.Equal(t, []string{"bob", "carol"}, toRemove)
+
+	toAdd, toRemove = reconcileChanges[string](nil, nil, nil, false)
+	assert.Nil(t, toAdd)
+	assert.Nil(t, toRemove)
+}
+
+func TestManagedElements(t *testing.T) {
+	current := []string{"alice", "bob", "carol"}
+	assert.Equal(t, current, managedElements(current, []string{"bob"}, true))
+	assert.Equal(t, []string{"bob"}, managedElements(current, []string{"bob", "dave"}, false))
+	assert.Nil(t, managedElements(current, nil, false))
+}
+
+func TestEscapePathSegment(t *testing.T) {
+	assert.Equal(t, "name", escapePathSegment("name"))
+	assert.Equal(t, "a~1b", escapePathSegment("a/b"))
+	assert.Equal(t, "a~0b", escapePathSegment("a~b"))
+	assert.Equal(t, "~01", escapePathSegment("~1"))
+}
+
+func TestDiffPatches_Scalars(t *testing.T) {
+	assert.Nil(t, diffPatches("/name", "a", "a"))
+	assert.Nil(t, diffPatches("/description", "", ""))
+	assert.Equal(t, []string{"replace /name"}, patchSummary(diffPatches("/name", "a", "b")))
+	assert.Equal(t, []string{"add /description"}, patchSummary(diffPatches("/description", "", "b")))
+	assert.Equal(t, []string{"remove /description"}, patchSummary(diffPatches("/description", "a", "")))
+	assert.Equal(t, []string{"replace /timeout_seconds"}, patchSummary(diffPatches("/timeout_seconds", 10, 20)))
+
+	// 0 and false are values, not absent values
+	assert.Equal(t, []string{"replace /max_retries"}, patchSummary(diffPatches("/max_retries", 3, 0)))
+	assert.Equal(t, []string{"replace /max_retries"}, patchSummary(diffPatches("/max_retries", 0, 3)))
+	assert.Equal(t, []string{"replace /enabled"}, patchSummary(diffPatches("/enabled", true, false)))
+	assert.Equal(t, []string{"replace /enabled"}, patchSummary(diffPatches("/enabled", false, true)))
+	assert.Nil(t, diffPatches("/max_retries", 0, 0))
+	assert.Equal(t, []string{"add /max_retries"}, patchSummary(diffPatches("/max_retries", nil, 0)))
+	assert.Equal(t, []string{"remove /max_retries"}, patchSummary(diffPatches("/max_retries", 0, nil)))
+
+	patches := diffPatches("/name", "a", "b")
+	assert.Equal(t, "b", patches[0].Value)
+}
+
+func TestDiffPatches_Maps(t *testing.T) {
+	old := map[string]interface{}{"env": "dev", "team": "data", "a/b": "x"}
+	val := map[string]interface{}{"env": "prod", "owner": "me", "a/b": "x"}
+
+	assert.Equal(t, []string{
+		"replace /labels/env",
+		"add /labels/owner",
+		"remove /labels/team",
+	}, patchSummary(diffPatches("/labels", old, val)))
+
+	assert.Equal(t, []string{"add /labels"}, patchSummary(diffPatches("/labels", map[string]interface{}{}, val)))
+	assert.Equal(t, []string{"remove /labels"}, patchSummary(diffPatches("/labels", old, map[string]interface{}{})))
+	assert.Equal(t, []string{"add /labels/a~1c"}, patchSummary(diffPatches("/labels", old, map[string]interface{}{"env": "dev", "team": "data", "a/b": "x", "a/c": "y"})))
+}
+
+func TestDiffPatches_MapScalars(t *testing.T) {
+	old := map[string]interface{}{"type": "spark", "num_executors": float64(2), "dynamic": true}
+	val := map[string]interface{}{"type": "spark", "num_executors": float64(0), "dynamic": false}
+	assert.Equal(t, []string{
+		"replace /options/dynamic",
+		"replace /options/num_executors",
+	}, patchSummary(diffPatches("/options", old, val)))
+	assert.Equal(t, []string{
+		"replace /options/dynamic",
+		"replace /options/num_executors",
+	}, patchSummary(diffPatches("/options", val, old)))
+}
+
+func TestDiffPatches_Lists(t *testing.T) {
+	old := []interface{}{"a", "b", "c"}
+
+	assert.Nil(t, diffPatches("/parameters", old, []interface{}{"a", "b", "c"}))
+	assert.Equal(t, []string{"replace /parameters/1"}, patchSummary(diffPatches("/parameters", old, []interface{}{"a", "x", "c"})))
+	assert.Equal(t, []string{"add /parameters/3", "add /parameters/4"}, patchSummary(diffPatches("/parameters", old, []interface{}{"a", "b", "c", "d", "e"})))
+	assert.Equal(t, []string{"remove /parameters/2", "remove /parameters/1"}, patchSummary(diffPatches("/parameters", old, []interface{}{"a"})))
+	// Empty elements are kept in place, otherwise the following indexes would move
+	assert.Equal(t, []string{"replace /parameters/0"}, patchSummary(diffPatches("/parameters", old, []interface{}{"", "b", "c"})))
+}
+
+func TestDiffPatches_Nested(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{"type": "file", "key": "a"},
+		map[string]interface{}{"type": "pypi", "dep": "numpy", "repo": ""},
+	}
+	val := []interface{}{
+		map[string]interface{}{"type": "file", "key": "b"},
+		map[string]interface{}{"type": "pypi", "dep": "numpy", "repo": "https://pypi.org"},
+	}
+	assert.Equal(t, []string{
+		"replace /libraries/0/key",
+		"add /libraries/1/repo",
+	}, patchSummary(diffPatches("/libraries", old, val)))
+
+	// An object changing of type is replaced as a whole
+	schedule := diffPatches("/schedule",
+		map[string]interface{}{"type": "once"},
+		map[string]interface{}{"type": "cron", "cron_expression": "0 0 * * *"},
+	)
+	assert.Equal(t, []string{"replace /schedule"}, patchSummary(schedule))
+	assert.Equal(t, map[string]interface{}{"type": "cron", "cron_expression": "0 0 * * *"}, schedule[0].Value)
+}
+
+func TestToJSONValue(t *testing.T) {
+	value, err := toJSONValue(map[string]interface{}{"count": 2, "names": []string{"a"}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"count": float64(2), "names": []interface{}{"a"}}, value)
+
+	value, err = toJSONValue(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
+
+func TestPatchFromResourceData_RemovedFromConfig(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"max_retries": {Type: schema.TypeInt, Optional: true},
+		"enabled":     {Type: schema.TypeBool, Optional: true, Default: true},
+	}
+	state := &terraform.InstanceState{ID: "id", Attributes: map[string]string{"max_retries": "3", "enabled": "false"}}
+	newData := func(config map[string]cty.Value) *schema.ResourceData {
+		diff, err := schema.InternalMap(resourceSchema).Diff(context.Background(), state, terraform.NewResourceConfigShimmed(cty.ObjectVal(config), schema.InternalMap(resourceSchema).CoreConfigSchema()), nil, nil, true)
+		assert.NoError(t, err)
+		diff.RawConfig = cty.ObjectVal(config)
+		d, err := schema.InternalMap(resourceSchema).Data(state, diff)
+		assert.NoError(t, err)
+		return d
+	}
+
+	// A number set to 0 is replaced
+	d := newData(map[string]cty.Value{"max_retries": cty.NumberIntVal(0), "enabled": cty.False})
+	assert.Equal(t, []string{"replace /max_retries"}, patchSummary(patchFromResourceData(d, "max_retries")))
+
+	// A number removed from the configuration is removed, an attribute with a default value is set to its default
+	d = newData(map[string]cty.Value{"max_retries": cty.NullVal(cty.Number), "enabled": cty.NullVal(cty.Bool)})
+	assert.Equal(t, []string{"remove /max_retries"}, patchSummary(patchFromResourceData(d, "max_retries")))
+	patches := patchFromResourceData(d, "enabled")
+	assert.Equal(t, []string{"replace /enabled"}, patchSummary(patches))
+	assert.Equal(t, true, patches[0].Value)
+}
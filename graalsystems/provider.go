@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptrace"
 	"os"
+	"slices"
+	"strings"
 
 	sdk "github.com/graalsystems/sdk/go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -18,6 +20,9 @@ import (
 
 var debug = os.Getenv("GS_DEBUG") != ""
 
+// defaultClientId is the OIDC client of the users, used by the credentials auth mode and by default by the device and jwt_bearer auth modes
+const defaultClientId = "graal-ui"
+
 // ProviderConfig config can be used to provide additional config when creating provider.
 type ProviderConfig struct {
 	// Meta can be used to override Meta that will be used by the provider.
@@ -38,63 +43,154 @@ func Provider(config *ProviderConfig) plugin.ProviderFunc {
 				"username": {
 					Type:        schema.TypeString,
 					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_USERNAME", nil),
 					Description: "The username (for credentials auth mode).",
 				},
 				"password": {
 					Type:        schema.TypeString,
 					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_PASSWORD", nil),
 					Description: "The password (for credentials auth mode).",
 				},
 				"application_id": {
 					Type:        schema.TypeString,
 					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_APPLICATION_ID", nil),
 					Description: "The application id (for application auth mode).",
 				},
 				"application_secret": {
 					Type:        schema.TypeString,
 					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_APPLICATION_SECRET", nil),
 					Description: "The application secret (for application auth mode).",
 				},
 				"tenant": {
 					Type:        schema.TypeString,
 					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_TENANT", nil),
 					Description: "The tenant ID.",
 				},
 				"api_url": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("GS_API_URL", nil),
+					Description:  "The API URL to use.",
+					ValidateFunc: validateProviderUrl,
+				},
+				"auth_url": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("GS_AUTH_URL", nil),
+					Description:  "The Auth URL to use.",
+					ValidateFunc: validateProviderUrl,
+				},
+				"auth_mode": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					Description: "The API URL to use.",
+					DefaultFunc: schema.EnvDefaultFunc("GS_AUTH_MODE", nil),
+					Description: fmt.Sprintf("The Auth mode to use, one of %q. Defaults to credentials.", authModes),
+					ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+						if !slices.Contains(authModes, val.(string)) {
+							errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, authModes, val))
+						}
+						return
+					},
 				},
-				"auth_url": {
+				"token": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					Description: "The Auth URL to use.",
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_TOKEN", nil),
+					Description: "A pre-issued access token (for token auth mode).",
 				},
-				"auth_mode": {
+				"client_id": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					Description: "The Auth mode to use.",
+					DefaultFunc: schema.EnvDefaultFunc("GS_CLIENT_ID", nil),
+					Description: fmt.Sprintf("The OIDC client (for device and jwt_bearer auth modes). Defaults to %s.", defaultClientId),
+				},
+				"oidc_token": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_OIDC_TOKEN", nil),
+					Description: "The OIDC token of the CI platform exchanged for an access token (for jwt_bearer auth mode).",
+				},
+				"oidc_token_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_OIDC_TOKEN_FILE", nil),
+					Description: "The file containing the OIDC token of the CI platform, read for every exchange (for jwt_bearer auth mode).",
+				},
+				"profile": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("GS_PROFILE", nil),
+					Description: "The profile of the config file (~/.graalsystems/config.yaml, or GS_CONFIG_FILE) completing the other arguments. The default profile is used if it exists.",
+				},
+				"max_retries": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("GS_MAX_RETRIES", defaultMaxRetries),
+					Description:  fmt.Sprintf("The number of retries of a request failing with a transient error, e.g. HTTP 429 or 503. Defaults to %d.", defaultMaxRetries),
+					ValidateFunc: validateNonNegativeInt,
+				},
+				"max_concurrent_requests": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("GS_MAX_CONCURRENT_REQUESTS", defaultMaxConcurrentRequests),
+					Description:  fmt.Sprintf("The number of requests sent to GraalSystems at the same time, 0 for no limit. Defaults to %d.", defaultMaxConcurrentRequests),
+					ValidateFunc: validateNonNegativeInt,
+				},
+				"requests_per_second": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("GS_REQUESTS_PER_SECOND", 0),
+					Description:  "The number of requests sent to GraalSystems per second, 0 for no limit. Defaults to 0.",
+					ValidateFunc: validateNonNegativeFloat,
+				},
+				"request_timeout_seconds": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("GS_REQUEST_TIMEOUT_SECONDS", defaultRequestTimeoutSeconds),
+					Description:  fmt.Sprintf("The timeout of each attempt of a request, in seconds, 0 for no timeout. Defaults to %d.", defaultRequestTimeoutSeconds),
+					ValidateFunc: validateNonNegativeInt,
 				},
 			},
 
 			ResourcesMap: map[string]*schema.Resource{
-				"graalsystems_project":   resourceGraalSystemsProject(),
-				"graalsystems_identity":  resourceGraalSystemsIdentity(),
-				"graalsystems_job":       resourceGraalSystemsJob(),
-				"graalsystems_user":      resourceGraalSystemsUser(),
-				"graalsystems_group":     resourceGraalSystemsGroup(),
-				"graalsystems_workspace": resourceGraalSystemsWorkspace(),
-				"graalsystems_workflow":  resourceGraalSystemsWorkflow(),
+				"graalsystems_project":            resourceGraalSystemsProject(),
+				"graalsystems_identity":           resourceGraalSystemsIdentity(),
+				"graalsystems_job":                resourceGraalSystemsJob(),
+				"graalsystems_user":               resourceGraalSystemsUser(),
+				"graalsystems_group":              resourceGraalSystemsGroup(),
+				"graalsystems_workspace":          resourceGraalSystemsWorkspace(),
+				"graalsystems_workflow":           resourceGraalSystemsWorkflow(),
+				"graalsystems_library":            resourceGraalSystemsLibrary(),
+				"graalsystems_secret":             resourceGraalSystemsSecret(),
+				"graalsystems_firewall_rule":      resourceGraalSystemsFirewallRule(),
+				"graalsystems_infrastructure":     resourceGraalSystemsInfrastructure(),
+				"graalsystems_group_membership":   resourceGraalSystemsGroupMembership(),
+				"graalsystems_project_permission": resourceGraalSystemsProjectPermission(),
+				"graalsystems_acl":                resourceGraalSystemsAcl(),
 			},
 
 			DataSourcesMap: map[string]*schema.Resource{
-				"graalsystems_project":   dataSourceGraalSystemsProject(),
-				"graalsystems_identity":  dataSourceGraalSystemsIdentity(),
-				"graalsystems_job":       dataSourceGraalSystemsJob(),
-				"graalsystems_user":      dataSourceGraalSystemsUser(),
-				"graalsystems_group":     dataSourceGraalSystemsGroup(),
-				"graalsystems_workspace": dataSourceGraalSystemsWorkspace(),
-				"graalsystems_workflow":  dataSourceGraalSystemsWorkflow(),
+				"graalsystems_project":          dataSourceGraalSystemsProject(),
+				"graalsystems_identity":         dataSourceGraalSystemsIdentity(),
+				"graalsystems_job":              dataSourceGraalSystemsJob(),
+				"graalsystems_user":             dataSourceGraalSystemsUser(),
+				"graalsystems_group":            dataSourceGraalSystemsGroup(),
+				"graalsystems_workspace":        dataSourceGraalSystemsWorkspace(),
+				"graalsystems_workflow":         dataSourceGraalSystemsWorkflow(),
+				"graalsystems_library":          dataSourceGraalSystemsLibrary(),
+				"graalsystems_secret":           dataSourceGraalSystemsSecret(),
+				"graalsystems_firewall_rule":    dataSourceGraalSystemsFirewallRule(),
+				"graalsystems_infrastructure":   dataSourceGraalSystemsInfrastructure(),
+				"graalsystems_instance_types":   dataSourceGraalSystemsInstanceTypes(),
+				"graalsystems_group_membership": dataSourceGraalSystemsGroupMembership(),
 			},
 		}
 
@@ -106,14 +202,10 @@ func Provider(config *ProviderConfig) plugin.ProviderFunc {
 				return config.Meta, nil
 			}
 
-			meta, err := buildMeta(ctx, &metaConfig{
+			return buildMeta(ctx, &metaConfig{
 				providerSchema:   data,
 				terraformVersion: terraformVersion,
 			})
-			if err != nil {
-				return nil, diag.FromErr(err)
-			}
-			return meta, nil
 		}
 
 		return p
@@ -135,64 +227,56 @@ type metaConfig struct {
 	httpClient       *http.Client
 }
 
-// providerConfigure creates the Meta object containing the SDK client.
-func buildMeta(ctx context.Context, config *metaConfig) (*Meta, error) {
-	tenant := config.providerSchema.Get("tenant").(string)
-	apiUrl := config.providerSchema.Get("api_url").(string)
-	authUrl := config.providerSchema.Get("auth_url").(string)
-	username := config.providerSchema.Get("username").(string)
-	password := config.providerSchema.Get("password").(string)
-	applicationId := config.providerSchema.Get("application_id").(string)
-	applicationSecret := config.providerSchema.Get("application_secret").(string)
-	authMode := config.providerSchema.Get("auth_mode").(string)
-	terraformVersion := config.terraformVersion
-
-	apiClient, err := buildApi(ctx, apiUrl, authUrl, terraformVersion, tenant, username, password, applicationId, applicationSecret, authMode)
+// buildMeta creates the Meta object containing the SDK client.
+// The settings are validated before authenticating, so that a missing setting is reported on its attribute.
+func buildMeta(ctx context.Context, config *metaConfig) (*Meta, diag.Diagnostics) {
+	values := make(map[string]string, len(profileAttributes))
+	for _, attribute := range profileAttributes {
+		values[attribute] = config.providerSchema.Get(attribute).(string)
+	}
+	settings, err := resolveProviderSettings(values, defaultConfigFilePath(), config.providerSchema.Get("profile").(string))
 	if err != nil {
-		return nil, err
+		return nil, diag.FromErr(err)
+	}
+	diagnostics := validateProviderSettings(settings)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+	httpClient := config.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: newRetryTransport(http.DefaultTransport, expandTransportConfig(config.providerSchema))}
+	}
+	apiClient, err := buildApi(ctx, config.terraformVersion, settings, httpClient)
+	if err != nil {
+		return nil, append(diagnostics, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Cannot authenticate to GraalSystems",
+			Detail:   err.Error(),
+		})
 	}
 
 	return &Meta{
 		apiClient: apiClient,
-		tenant:    tenant,
-	}, nil
+		tenant:    settings["tenant"],
+	}, diagnostics
 }
 
-func buildApi(ctx context.Context, apiUrl string, authUrl string, terraformVersion string, tenant string, username string, password string, appId string, appSecret string, authMode string) (*sdk.APIClient, error) {
+// buildApi creates the GraalSystems SDK client, authenticated with the auth mode of the settings.
+// All the requests, including the token requests, are sent with the HTTP client, so that they share its retries and limits.
+func buildApi(ctx context.Context, terraformVersion string, settings map[string]string, httpClient *http.Client) (*sdk.APIClient, error) {
 	////
 	// Create GraalSystems SDK client
 	////
 	servers := sdk.ServerConfigurations{}
 	servers = append(servers, sdk.ServerConfiguration{
-		URL: apiUrl,
+		URL: settings["api_url"],
 	})
 
-	authUrl, err := findRealm(ctx, terraformVersion, servers, tenant, authUrl)
+	client, err := buildAuthenticatedClient(ctx, terraformVersion, servers, settings, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
-	var client *http.Client
-
-	if authMode == "" || authMode == "credentials" {
-		cfg := oauth2.Config{
-			ClientID: "graal-ui",
-			Endpoint: oauth2.Endpoint{
-				TokenURL: authUrl,
-			},
-		}
-		client, _ = buildOAuth2ClientCredentials(ctx, cfg, username, password)
-	} else if authMode == "application" {
-		cfg := clientcredentials.Config{
-			ClientID:     appId,
-			ClientSecret: appSecret,
-			TokenURL:     authUrl,
-		}
-		client, _ = buildOAuth2ClientApplication(ctx, cfg)
-	} else {
-		return nil, errors.New(fmt.Sprintf("Invalid auth mode: %s", authMode))
-	}
-
 	configuration := sdk.Configuration{
 		UserAgent:  fmt.Sprintf("terraform-provider/%s terraform/%s", version, terraformVersion),
 		Debug:      debug,
@@ -204,12 +288,94 @@ func buildApi(ctx context.Context, apiUrl string, authUrl string, terraformVersi
 	return apiClient, nil
 }
 
-func findRealm(ctx context.Context, terraformVersion string, servers sdk.ServerConfigurations, tenant string, authUrl string) (string, error) {
-	tmpClient := &http.Client{}
+// buildAuthenticatedClient creates the HTTP client authenticating the requests with the auth mode of the settings.
+// The oauth2 package sends the requests of the authenticated client and the token requests with the HTTP client of the context.
+func buildAuthenticatedClient(ctx context.Context, terraformVersion string, servers sdk.ServerConfigurations, settings map[string]string, httpClient *http.Client) (*http.Client, error) {
+	authMode := settings["auth_mode"]
+	if authMode == "" {
+		authMode = authModeCredentials
+	}
+	if debug {
+		ctx = httptrace.WithClientTrace(ctx, buildClientTrace())
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	// The token is already issued, so the realm is not needed
+	if authMode == authModeToken {
+		source := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: settings["token"], TokenType: "Bearer"})
+		return oauth2.NewClient(context.WithoutCancel(ctx), source), nil
+	}
+	if !slices.Contains(authModes, authMode) {
+		return nil, errors.New(fmt.Sprintf("Invalid auth mode: %s", authMode))
+	}
+
+	authUrl, err := findRealm(ctx, terraformVersion, servers, settings["tenant"], settings["auth_url"], httpClient)
+	if err != nil {
+		return nil, err
+	}
+	clientId := settings["client_id"]
+	if clientId == "" {
+		clientId = defaultClientId
+	}
+
+	switch authMode {
+	case authModeApplication:
+		cfg := clientcredentials.Config{
+			ClientID:     settings["application_id"],
+			ClientSecret: settings["application_secret"],
+			TokenURL:     authUrl,
+		}
+		client, err := buildOAuth2ClientApplication(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot authenticate the application %s on %s: %s", settings["application_id"], authUrl, err)
+		}
+		return client, nil
+	case authModeDevice:
+		cfg := oauth2.Config{
+			ClientID: clientId,
+			Endpoint: oauth2.Endpoint{TokenURL: authUrl},
+			Scopes:   []string{"openid", "offline_access"},
+		}
+		source, err := newDeviceTokenSource(ctx, cfg, deviceAuthorizationUrl(authUrl), deviceTokenCachePath(authUrl, clientId))
+		if err != nil {
+			return nil, fmt.Errorf("cannot authenticate with the device login on %s: %s", authUrl, err)
+		}
+		return oauth2.NewClient(context.WithoutCancel(ctx), source), nil
+	case authModeJwtBearer:
+		assertion, err := federatedAssertion(settings["oidc_token"], settings["oidc_token_file"], clientId)
+		if err != nil {
+			return nil, err
+		}
+		source, err := newFederatedTokenSource(ctx, authUrl, clientId, assertion)
+		if err != nil {
+			return nil, fmt.Errorf("cannot authenticate with the OIDC token on %s: %s", authUrl, err)
+		}
+		return oauth2.NewClient(context.WithoutCancel(ctx), source), nil
+	default:
+		cfg := oauth2.Config{
+			ClientID: defaultClientId,
+			Endpoint: oauth2.Endpoint{
+				TokenURL: authUrl,
+			},
+		}
+		client, err := buildOAuth2ClientCredentials(ctx, cfg, settings["username"], settings["password"])
+		if err != nil {
+			return nil, fmt.Errorf("cannot authenticate the user %s on %s: %s", settings["username"], authUrl, err)
+		}
+		return client, nil
+	}
+}
+
+// deviceAuthorizationUrl returns the device authorization endpoint of the realm of the token endpoint
+func deviceAuthorizationUrl(tokenUrl string) string {
+	return strings.TrimSuffix(tokenUrl, "/token") + "/auth/device"
+}
+
+func findRealm(ctx context.Context, terraformVersion string, servers sdk.ServerConfigurations, tenant string, authUrl string, httpClient *http.Client) (string, error) {
 	tmpConfiguration := sdk.Configuration{
 		UserAgent:  fmt.Sprintf("terraform-provider/%s terraform/%s", version, terraformVersion),
 		Debug:      debug,
-		HTTPClient: tmpClient,
+		HTTPClient: httpClient,
 		Servers:    servers,
 	}
 	tmpApiClient := sdk.NewAPIClient(&tmpConfiguration)
@@ -217,39 +383,28 @@ func findRealm(ctx context.Context, terraformVersion string, servers sdk.ServerC
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
+	if t == nil || t.Realm == nil {
+		return "", fmt.Errorf("no realm found for the tenant %s", tenant)
+	}
 	authUrl = authUrl + "/realms/" + *t.Realm + "/protocol/openid-connect/token"
 	return authUrl, nil
 }
 
+// buildOAuth2ClientCredentials creates an HTTP client authenticated as the user.
+// Its token source refreshes the token or authenticates again transparently, so that long applies do not fail on token expiry.
 func buildOAuth2ClientCredentials(ctx context.Context, cfg oauth2.Config, username string, password string) (*http.Client, error) {
-	token, err := cfg.PasswordCredentialsToken(ctx, username, password)
+	source, err := newCredentialsTokenSource(ctx, cfg, username, password)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-
-	if !token.Valid() {
-		return nil, errors.New(fmt.Sprintf("Token invalid. Got: %#v", token))
-	}
-
-	var client *http.Client
-	if debug {
-		trace := buildClientTrace()
-		client = cfg.Client(httptrace.WithClientTrace(ctx, trace), token)
-	} else {
-		client = cfg.Client(ctx, token)
-	}
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
-	return client, nil
+	return oauth2.NewClient(context.WithoutCancel(ctx), source), nil
 }
 
+// buildOAuth2ClientApplication creates an HTTP client authenticated as the application.
+// A new token is requested when the current one expires, so the context must outlive the provider configuration.
 func buildOAuth2ClientApplication(ctx context.Context, cfg clientcredentials.Config) (*http.Client, error) {
-	var client *http.Client
-	if debug {
-		trace := buildClientTrace()
-		client = cfg.Client(httptrace.WithClientTrace(ctx, trace))
-	} else {
-		client = cfg.Client(ctx)
-	}
+	ctx = context.WithoutCancel(ctx)
+	client := cfg.Client(ctx)
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
 	_, err := cfg.Token(ctx)
 	if err != nil {
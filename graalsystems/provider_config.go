@@ -0,0 +1,232 @@
+package graalsystems
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	authModeCredentials = "credentials"
+	authModeApplication = "application"
+	authModeToken       = "token"
+	authModeDevice      = "device"
+	authModeJwtBearer   = "jwt_bearer"
+)
+
+var authModes = []string{authModeCredentials, authModeApplication, authModeToken, authModeDevice, authModeJwtBearer}
+
+// authModeRequiredAttributes lists the provider arguments required by each auth mode, in addition to the tenant and the URLs
+var authModeRequiredAttributes = map[string][]string{
+	authModeCredentials: {"username", "password"},
+	authModeApplication: {"application_id", "application_secret"},
+	authModeToken:       {"token"},
+	authModeDevice:      {},
+	authModeJwtBearer:   {},
+}
+
+// defaultProfile is the profile used when no profile is configured, if it exists in the config file
+const defaultProfile = "default"
+
+// profileAttributes lists the provider arguments that can be set in a profile
+var profileAttributes = []string{"username", "password", "application_id", "application_secret", "tenant", "api_url", "auth_url", "auth_mode",
+	"token", "client_id", "oidc_token", "oidc_token_file"}
+
+// configFile is the content of the config file, e.g.
+//
+//	profiles:
+//	  default:
+//	    tenant: acme
+//	    auth_mode: application
+//	    application_id: terraform
+//	    application_secret: XXX
+type configFile struct {
+	Profiles map[string]map[string]string `yaml:"profiles"`
+}
+
+// defaultConfigFilePath returns the path of the config file, overridden by GS_CONFIG_FILE, ~/.graalsystems/config.yaml otherwise
+func defaultConfigFilePath() string {
+	if path := os.Getenv("GS_CONFIG_FILE"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".graalsystems", "config.yaml")
+}
+
+// loadProfile returns the settings of a profile of the config file.
+// A missing config file or default profile is not an error, unless the profile has been explicitly configured.
+func loadProfile(path string, profile string) (map[string]string, error) {
+	explicit := profile != ""
+	if !explicit {
+		profile = defaultProfile
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot read the config file %s for the profile %s: %s", path, profile, err)
+	}
+	var config configFile
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("cannot parse the config file %s: %s", path, err)
+	}
+
+	settings, ok := config.Profiles[profile]
+	if !ok {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("the profile %s does not exist in the config file %s", profile, path)
+	}
+	for key := range settings {
+		if !slices.Contains(profileAttributes, key) {
+			return nil, fmt.Errorf("unknown setting %s in the profile %s of the config file %s", key, profile, path)
+		}
+	}
+	return settings, nil
+}
+
+// resolveProviderSettings completes the provider arguments, coming from the configuration or the environment variables,
+// with the settings of the profile
+func resolveProviderSettings(values map[string]string, path string, profile string) (map[string]string, error) {
+	settings, err := loadProfile(path, profile)
+	if err != nil {
+		return nil, err
+	}
+	resolved := make(map[string]string, len(profileAttributes))
+	for _, attribute := range profileAttributes {
+		resolved[attribute] = values[attribute]
+		if resolved[attribute] == "" {
+			resolved[attribute] = settings[attribute]
+		}
+	}
+	return resolved, nil
+}
+
+// validateProviderUrl checks that the value is an http or https URL
+func validateProviderUrl(val any, key string) (warns []string, errs []error) {
+	if u, err := url.Parse(val.(string)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%q must be a valid http or https URL, got: %s", key, val))
+	}
+	return
+}
+
+// validateNonNegativeInt checks that the value is a positive integer or zero
+func validateNonNegativeInt(val any, key string) (warns []string, errs []error) {
+	if v := val.(int); v < 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive integer or 0, got: %d", key, v))
+	}
+	return
+}
+
+// validateNonNegativeFloat checks that the value is a positive number or zero
+func validateNonNegativeFloat(val any, key string) (warns []string, errs []error) {
+	if v := val.(float64); v < 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive number or 0, got: %g", key, v))
+	}
+	return
+}
+
+// expandTransportConfig returns the retries, the rate limiting and the timeouts of the requests configured in the provider arguments
+func expandTransportConfig(d *schema.ResourceData) transportConfig {
+	return transportConfig{
+		maxRetries:            d.Get("max_retries").(int),
+		maxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+		requestsPerSecond:     d.Get("requests_per_second").(float64),
+		requestTimeout:        time.Duration(d.Get("request_timeout_seconds").(int)) * time.Second,
+	}
+}
+
+// validateProviderSettings checks the resolved provider arguments: the tenant, the URLs and the arguments of the auth mode are required.
+// The token auth mode does not need the auth URL, since the token is already issued.
+// The arguments of another auth mode are reported as warnings, since they are ignored.
+func validateProviderSettings(settings map[string]string) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+
+	authMode := settings["auth_mode"]
+	if authMode == "" {
+		authMode = authModeCredentials
+	}
+	required, ok := authModeRequiredAttributes[authMode]
+	if !ok {
+		return append(diagnostics, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Invalid auth mode",
+			Detail:        fmt.Sprintf("auth_mode must be one of %q, got: %s", authModes, authMode),
+			AttributePath: cty.GetAttrPath("auth_mode"),
+		})
+	}
+
+	common := []string{"tenant", "api_url", "auth_url"}
+	if authMode == authModeToken {
+		common = []string{"tenant", "api_url"}
+	}
+	for _, attribute := range append(common, required...) {
+		if settings[attribute] == "" {
+			diagnostics = append(diagnostics, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Missing %s", attribute),
+				Detail: fmt.Sprintf("%s is required with the %s auth mode. Set it in the provider block, with the %s environment variable or in a profile of the config file.",
+					attribute, authMode, providerEnvVar(attribute)),
+				AttributePath: cty.GetAttrPath(attribute),
+			})
+		}
+	}
+	for _, attribute := range []string{"api_url", "auth_url"} {
+		if settings[attribute] == "" {
+			continue
+		}
+		if _, errs := validateProviderUrl(settings[attribute], attribute); len(errs) > 0 {
+			diagnostics = append(diagnostics, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("Invalid %s", attribute),
+				Detail:        errs[0].Error(),
+				AttributePath: cty.GetAttrPath(attribute),
+			})
+		}
+	}
+	if _, _, ok := gitHubActionsTokenRequest(); authMode == authModeJwtBearer && settings["oidc_token"] == "" && settings["oidc_token_file"] == "" && !ok {
+		diagnostics = append(diagnostics, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Missing oidc_token",
+			Detail: "oidc_token or oidc_token_file is required with the jwt_bearer auth mode, unless running in GitHub Actions with the id-token: write permission. " +
+				"Set it in the provider block, with the GS_OIDC_TOKEN or GS_OIDC_TOKEN_FILE environment variables or in a profile of the config file.",
+			AttributePath: cty.GetAttrPath("oidc_token"),
+		})
+	}
+	for _, otherMode := range authModes {
+		if otherMode == authMode {
+			continue
+		}
+		for _, attribute := range authModeRequiredAttributes[otherMode] {
+			if settings[attribute] != "" {
+				diagnostics = append(diagnostics, diag.Diagnostic{
+					Severity:      diag.Warning,
+					Summary:       fmt.Sprintf("Ignored %s", attribute),
+					Detail:        fmt.Sprintf("%s is only used with the %s auth mode, but the auth mode is %s.", attribute, otherMode, authMode),
+					AttributePath: cty.GetAttrPath(attribute),
+				})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// providerEnvVar returns the environment variable a provider argument falls back to
+func providerEnvVar(attribute string) string {
+	return "GS_" + strings.ToUpper(attribute)
+}
@@ -0,0 +1,167 @@
+package graalsystems
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfigFile = `
+profiles:
+  default:
+    tenant: acme
+    auth_mode: credentials
+    username: jane
+  ci:
+    tenant: acme
+    auth_mode: application
+    application_id: terraform
+    application_secret: secret
+  typo:
+    tennant: acme
+`
+
+func writeTestConfigFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testConfigFile), 0600))
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := writeTestConfigFile(t)
+
+	settings, err := loadProfile(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "jane", settings["username"])
+
+	settings, err = loadProfile(path, "ci")
+	assert.NoError(t, err)
+	assert.Equal(t, "terraform", settings["application_id"])
+
+	_, err = loadProfile(path, "staging")
+	assert.ErrorContains(t, err, "the profile staging does not exist")
+
+	_, err = loadProfile(path, "typo")
+	assert.ErrorContains(t, err, "unknown setting tennant")
+
+	// A missing config file is only an error if a profile is configured
+	missing := filepath.Join(t.TempDir(), "config.yaml")
+	settings, err = loadProfile(missing, "")
+	assert.NoError(t, err)
+	assert.Nil(t, settings)
+	_, err = loadProfile(missing, "ci")
+	assert.Error(t, err)
+}
+
+func TestResolveProviderSettings(t *testing.T) {
+	path := writeTestConfigFile(t)
+
+	// The configuration and the environment variables take precedence over the profile
+	settings, err := resolveProviderSettings(map[string]string{"tenant": "other", "api_url": "https://api.graal.systems/api/v1"}, path, "ci")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", settings["tenant"])
+	assert.Equal(t, "https://api.graal.systems/api/v1", settings["api_url"])
+	assert.Equal(t, "application", settings["auth_mode"])
+	assert.Equal(t, "secret", settings["application_secret"])
+	assert.Equal(t, "", settings["username"])
+}
+
+func TestValidateProviderSettings(t *testing.T) {
+	settings := map[string]string{
+		"tenant":   "acme",
+		"api_url":  "https://api.graal.systems/api/v1",
+		"auth_url": "https://identity.graal.systems",
+		"username": "jane",
+		"password": "secret",
+	}
+	assert.Empty(t, validateProviderSettings(settings))
+
+	// With the application auth mode, the application secret is missing and the credentials are ignored
+	settings["auth_mode"] = authModeApplication
+	settings["application_id"] = "terraform"
+	diagnostics := validateProviderSettings(settings)
+	assert.True(t, diagnostics.HasError())
+	assert.Len(t, diagnostics, 3)
+	assert.Equal(t, "Missing application_secret", diagnostics[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("application_secret"), diagnostics[0].AttributePath)
+	assert.Contains(t, diagnostics[0].Detail, "GS_APPLICATION_SECRET")
+	assert.Equal(t, diag.Warning, diagnostics[1].Severity)
+	assert.Equal(t, cty.GetAttrPath("username"), diagnostics[1].AttributePath)
+
+	diagnostics = validateProviderSettings(map[string]string{"auth_mode": "kerberos"})
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, cty.GetAttrPath("auth_mode"), diagnostics[0].AttributePath)
+
+	diagnostics = validateProviderSettings(map[string]string{"api_url": "api.graal.systems"})
+	assert.Len(t, diagnostics, 5)
+	assert.Equal(t, "Invalid api_url", diagnostics[4].Summary)
+}
+
+func TestValidateProviderSettingsAuthModes(t *testing.T) {
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	settings := map[string]string{
+		"tenant":  "acme",
+		"api_url": "https://api.graal.systems/api/v1",
+	}
+
+	// The token auth mode does not need the auth URL
+	settings["auth_mode"] = authModeToken
+	settings["token"] = "eyJhbGciOi"
+	assert.Empty(t, validateProviderSettings(settings))
+
+	settings["auth_url"] = "https://identity.graal.systems"
+	settings["auth_mode"] = authModeDevice
+	diagnostics := validateProviderSettings(settings)
+	assert.False(t, diagnostics.HasError())
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, cty.GetAttrPath("token"), diagnostics[0].AttributePath)
+
+	delete(settings, "token")
+	settings["auth_mode"] = authModeJwtBearer
+	diagnostics = validateProviderSettings(settings)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, cty.GetAttrPath("oidc_token"), diagnostics[0].AttributePath)
+
+	settings["oidc_token_file"] = "/var/run/secrets/tokens/graalsystems"
+	assert.Empty(t, validateProviderSettings(settings))
+
+	// GitHub Actions provides the OIDC token only with both the request URL and its bearer token
+	delete(settings, "oidc_token_file")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "https://pipelines.actions.githubusercontent.com/token")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+	diagnostics = validateProviderSettings(settings)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, cty.GetAttrPath("oidc_token"), diagnostics[0].AttributePath)
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+	assert.Empty(t, validateProviderSettings(settings))
+}
+
+func TestExpandTransportConfig(t *testing.T) {
+	providerSchema := Provider(DefaultProviderConfig())().Schema
+
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{})
+	assert.Equal(t, transportConfig{
+		maxRetries:            defaultMaxRetries,
+		maxConcurrentRequests: defaultMaxConcurrentRequests,
+		requestTimeout:        defaultRequestTimeoutSeconds * time.Second,
+	}, expandTransportConfig(d))
+
+	t.Setenv("GS_MAX_RETRIES", "2")
+	t.Setenv("GS_REQUESTS_PER_SECOND", "0.5")
+	d = schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+		"max_concurrent_requests": 0,
+		"request_timeout_seconds": 5,
+	})
+	assert.Equal(t, transportConfig{
+		maxRetries:        2,
+		requestsPerSecond: 0.5,
+		requestTimeout:    5 * time.Second,
+	}, expandTransportConfig(d))
+}
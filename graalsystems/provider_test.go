@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,7 +13,13 @@ import (
 func TestProvider_BuildApiWithCredentials(t *testing.T) {
 
 	ctx := context.Background()
-	api, err := buildApi(ctx, "http://172.27.0.1:4200/api/v1", "http://localhost:8089", "version", "platform-vincent-internal", "vdevillers", "devillerspwd", "", "", "")
+	api, err := buildApi(ctx, "version", map[string]string{
+		"api_url":  "http://172.27.0.1:4200/api/v1",
+		"auth_url": "http://localhost:8089",
+		"tenant":   "platform-vincent-internal",
+		"username": "vdevillers",
+		"password": "devillerspwd",
+	}, http.DefaultClient)
 	assert.Nil(t, err)
 	if err != nil {
 		log.Fatal(err)
@@ -29,3 +36,7 @@ func TestProvider_BuildApiWithCredentials(t *testing.T) {
 
 	}
 }
+
+func TestProvider_InternalValidate(t *testing.T) {
+	assert.Nil(t, Provider(DefaultProviderConfig())().InternalValidate())
+}
@@ -0,0 +1,133 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// aclResourceTypes lists the types of objects whose access is managed by the ACLs
+var aclResourceTypes = []string{permissionTargetWorkflow, permissionTargetWorkspace}
+
+// resourceGraalSystemsAcl defines the schema for the ACL resource, managing the permissions of a workflow or a workspace
+// An authoritative ACL owns every binding of the object, and removes the ones it does not list.
+// A non-authoritative ACL only adds and removes the bindings it lists.
+func resourceGraalSystemsAcl() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsAclCreate,
+		ReadContext:   resourceGraalSystemsAclRead,
+		UpdateContext: resourceGraalSystemsAclUpdate,
+		DeleteContext: resourceGraalSystemsAclDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGraalSystemsAclImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"resource_type": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: fmt.Sprintf("The type of the object, one of %q", aclResourceTypes),
+				ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+					if !slices.Contains(aclResourceTypes, val.(string)) {
+						errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, aclResourceTypes, val))
+					}
+					return
+				},
+			},
+			"resource_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the workflow or the workspace",
+			},
+			"permission": permissionSchema(),
+			"authoritative": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "Whether the ACL owns every binding of the object, removing the bindings it does not list",
+			},
+		},
+	}
+}
+
+// parseAclId splits the id of an ACL, e.g. `workflow/<workflow id>`, into the type and the id of its object
+func parseAclId(id string) (string, string, error) {
+	resourceType, resourceId, found := strings.Cut(id, "/")
+	if !found || resourceId == "" || !slices.Contains(aclResourceTypes, resourceType) {
+		return "", "", fmt.Errorf("invalid ACL id %q, expected <resource_type>/<resource_id> with a resource type among %q", id, aclResourceTypes)
+	}
+	return resourceType, resourceId, nil
+}
+
+// resourceGraalSystemsAclImport imports the bindings of an object as an authoritative ACL
+func resourceGraalSystemsAclImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	resourceType, resourceId, err := parseAclId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	_ = d.Set("resource_type", resourceType)
+	_ = d.Set("resource_id", resourceId)
+	_ = d.Set("authoritative", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceGraalSystemsAclCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	resourceType := d.Get("resource_type").(string)
+	resourceId := d.Get("resource_id").(string)
+	if err := createPermissions(d, meta, resourceType, resourceId); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(resourceType + "/" + resourceId)
+
+	return resourceGraalSystemsAclRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsAclRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	resourceType, resourceId, err := parseAclId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	found, err := readPermissions(d, meta, resourceType, resourceId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !found {
+		d.SetId("")
+		return nil
+	}
+	_ = d.Set("resource_type", resourceType)
+	_ = d.Set("resource_id", resourceId)
+
+	return nil
+}
+
+func resourceGraalSystemsAclUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	if err := updatePermissions(d, meta, d.Get("resource_type").(string), d.Get("resource_id").(string)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceGraalSystemsAclRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsAclDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	if err := deletePermissions(d, meta, d.Get("resource_type").(string), d.Get("resource_id").(string)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
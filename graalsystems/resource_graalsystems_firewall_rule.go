@@ -0,0 +1,235 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	firewallProtocolTcp  = "tcp"
+	firewallProtocolUdp  = "udp"
+	firewallProtocolIcmp = "icmp"
+	firewallProtocolAll  = "all"
+)
+
+const (
+	firewallDirectionInbound  = "inbound"
+	firewallDirectionOutbound = "outbound"
+)
+
+var firewallProtocols = []string{firewallProtocolTcp, firewallProtocolUdp, firewallProtocolIcmp, firewallProtocolAll}
+var firewallDirections = []string{firewallDirectionInbound, firewallDirectionOutbound}
+
+// resourceGraalSystemsFirewallRule defines the schema for the firewall rule resource
+// A firewall rule allows the traffic of a CIDR to a project or a workspace
+func resourceGraalSystemsFirewallRule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsFirewallRuleCreate,
+		ReadContext:   resourceGraalSystemsFirewallRuleRead,
+		UpdateContext: resourceGraalSystemsFirewallRuleUpdate,
+		DeleteContext: resourceGraalSystemsFirewallRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: customizeDiffFirewallRulePortRange,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the firewall rule",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description of the firewall rule",
+			},
+			"cidr": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The IPv4 or IPv6 CIDR block the rule applies to, e.g. `10.0.0.0/16`",
+				ValidateFunc: validateCidr,
+			},
+			"protocol": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     firewallProtocolTcp,
+				Description: fmt.Sprintf("The protocol of the traffic, one of %q", firewallProtocols),
+				ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+					if !slices.Contains(firewallProtocols, val.(string)) {
+						errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, firewallProtocols, val))
+					}
+					return
+				},
+			},
+			"port_range": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The port, e.g. `443`, or the inclusive range of ports, e.g. `8000-8080`, the rule applies to. All the ports if empty. Only used if protocol is `tcp` or `udp`",
+				ValidateFunc: validatePortRange,
+			},
+			"direction": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     firewallDirectionInbound,
+				Description: fmt.Sprintf("The direction of the traffic, one of %q", firewallDirections),
+				ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+					if !slices.Contains(firewallDirections, val.(string)) {
+						errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, firewallDirections, val))
+					}
+					return
+				},
+			},
+			"project_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "The id of the project the rule applies to",
+				ExactlyOneOf: []string{"project_id", "workspace_id"},
+			},
+			"workspace_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "The id of the workspace the rule applies to",
+				ExactlyOneOf: []string{"project_id", "workspace_id"},
+			},
+		},
+	}
+}
+
+// validateCidr checks that the value is a CIDR block, written with its network address
+func validateCidr(val any, key string) (warns []string, errs []error) {
+	ip, network, err := net.ParseCIDR(val.(string))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a valid CIDR block, got: %s", key, val))
+		return
+	}
+	if !ip.Equal(network.IP) {
+		errs = append(errs, fmt.Errorf("%q must start at the network address of the block, %s instead of %s", key, network, val))
+	}
+	return
+}
+
+// parsePortRange returns the first and the last port of a port range, e.g. `443` or `8000-8080`
+func parsePortRange(portRange string) (int, int, error) {
+	first, last, isRange := strings.Cut(portRange, "-")
+	from, err := strconv.Atoi(first)
+	if err != nil || from < 1 || from > 65535 {
+		return 0, 0, fmt.Errorf("%q is not a valid port", first)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	to, err := strconv.Atoi(last)
+	if err != nil || to < 1 || to > 65535 {
+		return 0, 0, fmt.Errorf("%q is not a valid port", last)
+	}
+	if to < from {
+		return 0, 0, fmt.Errorf("the range %s ends before it starts", portRange)
+	}
+	return from, to, nil
+}
+
+func validatePortRange(val any, key string) (warns []string, errs []error) {
+	if _, _, err := parsePortRange(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a port or a range of ports between 1 and 65535: %s", key, err))
+	}
+	return
+}
+
+// customizeDiffFirewallRulePortRange rejects port ranges on the protocols without ports
+func customizeDiffFirewallRulePortRange(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	protocol := d.Get("protocol").(string)
+	if d.Get("port_range").(string) != "" && protocol != firewallProtocolTcp && protocol != firewallProtocolUdp {
+		return fmt.Errorf("port_range is not allowed for protocol %s", protocol)
+	}
+	return nil
+}
+
+func resourceGraalSystemsFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	name := d.Get("name").(string)
+	description := d.Get("description").(string)
+	cidr := d.Get("cidr").(string)
+	protocol := d.Get("protocol").(string)
+	direction := d.Get("direction").(string)
+	rule := &sdk.FirewallRule{
+		Name:        &name,
+		Description: &description,
+		Cidr:        &cidr,
+		PortRange:   expandStringPtr(d.Get("port_range")),
+		Protocol:    &protocol,
+		Direction:   &direction,
+		ProjectId:   expandStringPtr(d.Get("project_id")),
+		WorkspaceId: expandStringPtr(d.Get("workspace_id")),
+	}
+	result, _, err := apiClient.FirewallRuleAPI.CreateFirewallRule(context.Background()).XTenant(meta.tenant).FirewallRule(*rule).Execute()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(*result.Id)
+
+	return resourceGraalSystemsFirewallRuleRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsFirewallRuleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	res, _, err := apiClient.FirewallRuleAPI.FindFirewallRuleById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil {
+		if is404Error(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	_ = d.Set("name", res.Name)
+	_ = d.Set("description", res.Description)
+	_ = d.Set("cidr", res.Cidr)
+	_ = d.Set("port_range", flattenStringPtr(res.PortRange))
+	_ = d.Set("protocol", res.Protocol)
+	_ = d.Set("direction", res.Direction)
+	_ = d.Set("project_id", flattenStringPtr(res.ProjectId))
+	_ = d.Set("workspace_id", flattenStringPtr(res.WorkspaceId))
+
+	return nil
+}
+
+func resourceGraalSystemsFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	if patches := patchesFromResourceData(d, "name", "description", "cidr", "port_range", "protocol", "direction"); len(patches) > 0 {
+		_, _, err := apiClient.FirewallRuleAPI.UpdateFirewallRule(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceGraalSystemsFirewallRuleRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsFirewallRuleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	_, err := apiClient.FirewallRuleAPI.DeleteFirewallRuleById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil && !is404Error(err) {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
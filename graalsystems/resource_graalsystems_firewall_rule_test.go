@@ -0,0 +1,33 @@
+package graalsystems
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCidr(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/16", "0.0.0.0/0", "192.168.1.10/32", "2001:db8::/32"} {
+		_, errs := validateCidr(cidr, "cidr")
+		assert.Empty(t, errs, cidr)
+	}
+	for _, cidr := range []string{"", "10.0.0.0", "10.0.0.0/33", "10.0.0.1/24", "my-network"} {
+		_, errs := validateCidr(cidr, "cidr")
+		assert.NotEmpty(t, errs, cidr)
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	from, to, err := parsePortRange("443")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{443, 443}, []int{from, to})
+
+	from, to, err = parsePortRange("8000-8080")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{8000, 8080}, []int{from, to})
+
+	for _, portRange := range []string{"", "0", "65536", "http", "8080-8000", "80-", "-80", "1-2-3"} {
+		_, _, err := parsePortRange(portRange)
+		assert.NotNil(t, err, portRange)
+	}
+}
@@ -0,0 +1,181 @@
+package graalsystems
+
+import (
+	"context"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	groupMemberTypeUser     = "user"
+	groupMemberTypeIdentity = "identity"
+)
+
+// groupMemberAttributes maps the member types to the attributes listing them
+var groupMemberAttributes = map[string]string{
+	groupMemberTypeUser:     "user_ids",
+	groupMemberTypeIdentity: "identity_ids",
+}
+
+// resourceGraalSystemsGroupMembership defines the schema for the group membership resource
+// An authoritative membership owns every member of the group, and removes the ones it does not list.
+// A non-authoritative membership only adds and removes the members it lists.
+func resourceGraalSystemsGroupMembership() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsGroupMembershipCreate,
+		ReadContext:   resourceGraalSystemsGroupMembershipRead,
+		UpdateContext: resourceGraalSystemsGroupMembershipUpdate,
+		DeleteContext: resourceGraalSystemsGroupMembershipDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGraalSystemsGroupMembershipImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the group",
+			},
+			"user_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The ids of the users member of the group",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"identity_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The ids of the identities member of the group",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"authoritative": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "Whether the membership owns every member of the group, removing the members it does not list",
+			},
+		},
+	}
+}
+
+// resourceGraalSystemsGroupMembershipImport imports the members of a group as an authoritative membership
+func resourceGraalSystemsGroupMembershipImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	_ = d.Set("group_id", d.Id())
+	_ = d.Set("authoritative", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceGraalSystemsGroupMembershipCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	groupId := d.Get("group_id").(string)
+	authoritative := d.Get("authoritative").(bool)
+	for memberType, attribute := range groupMemberAttributes {
+		wanted := toStringList(d.Get(attribute).(*schema.Set).List())
+		if err := reconcileGroupMembers(meta, groupId, memberType, wanted, nil, authoritative); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(groupId)
+
+	return resourceGraalSystemsGroupMembershipRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsGroupMembershipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	members, _, err := apiClient.GroupAPI.FindMembersByGroupId(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil {
+		if is404Error(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	authoritative := d.Get("authoritative").(bool)
+	for memberType, attribute := range groupMemberAttributes {
+		// A non-authoritative membership ignores the members managed elsewhere
+		managed := toStringList(d.Get(attribute).(*schema.Set).List())
+		_ = d.Set(attribute, managedElements(groupMemberIds(members, memberType), managed, authoritative))
+	}
+	_ = d.Set("group_id", d.Id())
+
+	return nil
+}
+
+func resourceGraalSystemsGroupMembershipUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	authoritative := d.Get("authoritative").(bool)
+	for memberType, attribute := range groupMemberAttributes {
+		if !d.HasChange(attribute) {
+			continue
+		}
+		old, val := d.GetChange(attribute)
+		managed := toStringList(old.(*schema.Set).List())
+		wanted := toStringList(val.(*schema.Set).List())
+		if err := reconcileGroupMembers(meta, d.Id(), memberType, wanted, managed, authoritative); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceGraalSystemsGroupMembershipRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsGroupMembershipDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	for memberType, attribute := range groupMemberAttributes {
+		managed := toStringList(d.Get(attribute).(*schema.Set).List())
+		if err := reconcileGroupMembers(meta, d.Id(), memberType, nil, managed, false); err != nil {
+			if is404Error(err) {
+				return nil
+			}
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+// reconcileGroupMembers adds the wanted members of a type missing from the group, and removes the managed members not wanted anymore.
+// An authoritative reconciliation removes every member of the type that is not wanted.
+func reconcileGroupMembers(meta *Meta, groupId string, memberType string, wanted []string, managed []string, authoritative bool) error {
+	apiClient := meta.apiClient
+
+	members, _, err := apiClient.GroupAPI.FindMembersByGroupId(context.Background(), groupId).XTenant(meta.tenant).Execute()
+	if err != nil {
+		return err
+	}
+	toAdd, toRemove := reconcileChanges(groupMemberIds(members, memberType), managed, wanted, authoritative)
+
+	for _, memberId := range toAdd {
+		member := sdk.GroupMember{Id: &memberId, Type: &memberType}
+		if _, err := apiClient.GroupAPI.AddMemberToGroup(context.Background(), groupId).XTenant(meta.tenant).GroupMember(member).Execute(); err != nil {
+			return err
+		}
+	}
+	for _, memberId := range toRemove {
+		if _, err := apiClient.GroupAPI.RemoveMemberFromGroup(context.Background(), groupId, memberId).XTenant(meta.tenant).Execute(); err != nil && !is404Error(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupMemberIds returns the ids of the members of a type
+func groupMemberIds(members []sdk.GroupMember, memberType string) []string {
+	var ids []string
+	for _, member := range members {
+		if flattenStringPtr(member.Type) == memberType {
+			ids = append(ids, flattenStringPtr(member.Id))
+		}
+	}
+	return ids
+}
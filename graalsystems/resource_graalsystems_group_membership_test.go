@@ -0,0 +1,19 @@
+package graalsystems
+
+import (
+	"testing"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMemberIds(t *testing.T) {
+	member := func(id string, memberType string) sdk.GroupMember {
+		return sdk.GroupMember{Id: &id, Type: &memberType}
+	}
+	members := []sdk.GroupMember{member("alice", groupMemberTypeUser), member("ci", groupMemberTypeIdentity), member("bob", groupMemberTypeUser)}
+
+	assert.Equal(t, []string{"alice", "bob"}, groupMemberIds(members, groupMemberTypeUser))
+	assert.Equal(t, []string{"ci"}, groupMemberIds(members, groupMemberTypeIdentity))
+	assert.Equal(t, []string{"bob"}, managedElements(groupMemberIds(members, groupMemberTypeUser), []string{"bob", "dave"}, false))
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// resourceGraalSystemsIdentity defines the schema for the identity resource
+// An identity holds at most one kind of cloud credentials. The secrets of the credentials are write-only:
+// they are never returned by the API, so their value is kept from the configuration.
 func resourceGraalSystemsIdentity() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceGraalSystemsIdentityCreate,
@@ -28,6 +31,10 @@ func resourceGraalSystemsIdentity() *schema.Resource {
 				Optional:    true,
 				Description: "The description of the identity",
 			},
+			credentialKindAws:        awsCredentialSchema(),
+			credentialKindAzure:      azureCredentialSchema(),
+			credentialKindGcp:        gcpCredentialSchema(),
+			credentialKindKubernetes: kubernetesCredentialSchema(),
 		},
 	}
 }
@@ -42,6 +49,7 @@ func resourceGraalSystemsIdentityCreate(ctx context.Context, d *schema.ResourceD
 		Name:        &name,
 		Description: &description,
 	}
+	defineCredentials(d, identity)
 	result, _, err := apiClient.IdentityAPI.CreateIdentity(context.Background()).XTenant(meta.tenant).Identity(*identity).Execute()
 	if err != nil {
 		return diag.FromErr(err)
@@ -67,6 +75,7 @@ func resourceGraalSystemsIdentityRead(ctx context.Context, d *schema.ResourceDat
 
 	_ = d.Set("name", res.Name)
 	_ = d.Set("description", res.Description)
+	readCredentials(d, res)
 
 	return nil
 }
@@ -75,18 +84,15 @@ func resourceGraalSystemsIdentityUpdate(ctx context.Context, d *schema.ResourceD
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
-	if d.HasChange("name") {
-		path := "/name"
-
-		value := d.Get("name").(string)
+	patches := patchesFromResourceData(d, "name", "description")
+	credentialPatches, err := patchCredentials(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	patches = append(patches, credentialPatches...)
 
-		patch := &sdk.Patch{
-			Op:    nil,
-			Path:  &path,
-			Value: &value,
-		}
-		patchs := &[]sdk.Patch{*patch}
-		_, _, err := apiClient.IdentityAPI.UpdateIdentity(context.Background(), d.Id()).XTenant(meta.tenant).Patch(*patchs).Execute()
+	if len(patches) > 0 {
+		_, _, err := apiClient.IdentityAPI.UpdateIdentity(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -0,0 +1,337 @@
+package graalsystems
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	credentialKindAws        = "aws"
+	credentialKindAzure      = "azure"
+	credentialKindGcp        = "gcp"
+	credentialKindKubernetes = "kubernetes"
+)
+
+var credentialKinds = []string{credentialKindAws, credentialKindAzure, credentialKindGcp, credentialKindKubernetes}
+
+// credentialSecretFields lists the attributes of each credential block that are never returned by the API.
+// Their value is kept from the state when reading the identity.
+var credentialSecretFields = map[string][]string{
+	credentialKindAws:        {"secret_access_key"},
+	credentialKindAzure:      {"client_secret"},
+	credentialKindGcp:        {"service_account_json"},
+	credentialKindKubernetes: {"token"},
+}
+
+var awsRoleArnRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/.+$`)
+
+// credentialSchema defines a credential block of the identity, conflicting with the other kinds of credentials
+func credentialSchema(kind string, description string, fields map[string]*schema.Schema) *schema.Schema {
+	var conflicts []string
+	for _, other := range credentialKinds {
+		if other != kind {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		Description:   description,
+		ConflictsWith: conflicts,
+		Elem:          &schema.Resource{Schema: fields},
+	}
+}
+
+// awsCredentialSchema defines the AWS credentials, either an access key or a role to assume
+func awsCredentialSchema() *schema.Schema {
+	return credentialSchema(credentialKindAws, "The AWS credentials of the identity, either an access key or a role to assume", map[string]*schema.Schema{
+		"access_key_id": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The id of the AWS access key",
+			RequiredWith:  []string{"aws.0.secret_access_key"},
+			ConflictsWith: []string{"aws.0.role_arn"},
+			AtLeastOneOf:  []string{"aws.0.access_key_id", "aws.0.role_arn"},
+		},
+		"secret_access_key": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			Description:  "The secret of the AWS access key",
+			RequiredWith: []string{"aws.0.access_key_id"},
+		},
+		"role_arn": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The ARN of the AWS role assumed by the identity",
+			ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+				if !awsRoleArnRegexp.MatchString(val.(string)) {
+					errs = append(errs, fmt.Errorf("%q must be an IAM role ARN, e.g. arn:aws:iam::123456789012:role/graalsystems, got: %s", key, val))
+				}
+				return
+			},
+		},
+		"external_id": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The external id required to assume the AWS role",
+			RequiredWith:  []string{"aws.0.role_arn"},
+			ConflictsWith: []string{"aws.0.access_key_id"},
+		},
+	})
+}
+
+// azureCredentialSchema defines the Azure service principal credentials
+func azureCredentialSchema() *schema.Schema {
+	return credentialSchema(credentialKindAzure, "The Azure service principal of the identity", map[string]*schema.Schema{
+		"tenant_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The id of the Azure tenant of the service principal",
+		},
+		"client_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The client id of the service principal",
+		},
+		"client_secret": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Sensitive:   true,
+			Description: "The client secret of the service principal",
+		},
+		"subscription_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The id of the Azure subscription used by the identity",
+		},
+	})
+}
+
+// gcpCredentialSchema defines the GCP service account credentials
+func gcpCredentialSchema() *schema.Schema {
+	return credentialSchema(credentialKindGcp, "The GCP service account of the identity", map[string]*schema.Schema{
+		"service_account_json": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Sensitive:    true,
+			Description:  "The JSON key of the service account",
+			ValidateFunc: validateGcpServiceAccountJson,
+		},
+		"client_email": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The email address of the service account",
+		},
+		"project_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The id of the GCP project of the service account",
+		},
+	})
+}
+
+// kubernetesCredentialSchema defines the Kubernetes service account credentials
+func kubernetesCredentialSchema() *schema.Schema {
+	return credentialSchema(credentialKindKubernetes, "The Kubernetes service account of the identity", map[string]*schema.Schema{
+		"host": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The URL of the Kubernetes API server",
+			ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+				if u, err := url.Parse(val.(string)); err != nil || u.Scheme != "https" || u.Host == "" {
+					errs = append(errs, fmt.Errorf("%q must be a valid https URL, got: %s", key, val))
+				}
+				return
+			},
+		},
+		"namespace": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The namespace of the service account",
+		},
+		"service_account": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The name of the service account",
+		},
+		"token": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Sensitive:   true,
+			Description: "The token of the service account",
+		},
+		"cluster_ca_certificate": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The PEM encoded CA certificate of the Kubernetes cluster",
+		},
+	})
+}
+
+// validateGcpServiceAccountJson checks that the value is the JSON key of a service account
+func validateGcpServiceAccountJson(val any, key string) (warns []string, errs []error) {
+	var serviceAccount struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	// The value is sensitive, so it is never part of the error
+	if err := json.Unmarshal([]byte(val.(string)), &serviceAccount); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a valid JSON document: %s", key, err))
+		return
+	}
+	if serviceAccount.Type != "service_account" || serviceAccount.ClientEmail == "" || serviceAccount.PrivateKey == "" {
+		errs = append(errs, fmt.Errorf("%q must be the JSON key of a service account, with the type service_account, a client_email and a private_key", key))
+	}
+	return
+}
+
+// credentialBlock returns the attributes of a credential block, or nil if the block is not set
+func credentialBlock(v interface{}) map[string]interface{} {
+	if blocks := v.([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+		return blocks[0].(map[string]interface{})
+	}
+	return nil
+}
+
+// defineAwsCredentials converts the aws block to the AWS credentials of the API
+func defineAwsCredentials(v interface{}) *sdk.AwsCredentials {
+	block := credentialBlock(v)
+	if block == nil {
+		return nil
+	}
+	return &sdk.AwsCredentials{
+		AccessKeyId:     expandStringPtr(block["access_key_id"]),
+		SecretAccessKey: expandStringPtr(block["secret_access_key"]),
+		RoleArn:         expandStringPtr(block["role_arn"]),
+		ExternalId:      expandStringPtr(block["external_id"]),
+	}
+}
+
+// defineAzureCredentials converts the azure block to the Azure credentials of the API
+func defineAzureCredentials(v interface{}) *sdk.AzureCredentials {
+	block := credentialBlock(v)
+	if block == nil {
+		return nil
+	}
+	return &sdk.AzureCredentials{
+		TenantId:       expandStringPtr(block["tenant_id"]),
+		ClientId:       expandStringPtr(block["client_id"]),
+		ClientSecret:   expandStringPtr(block["client_secret"]),
+		SubscriptionId: expandStringPtr(block["subscription_id"]),
+	}
+}
+
+// defineGcpCredentials converts the gcp block to the GCP credentials of the API.
+// The client email and the project are extracted from the key by the API.
+func defineGcpCredentials(v interface{}) *sdk.GcpCredentials {
+	block := credentialBlock(v)
+	if block == nil {
+		return nil
+	}
+	return &sdk.GcpCredentials{
+		ServiceAccountJson: expandStringPtr(block["service_account_json"]),
+	}
+}
+
+// defineKubernetesCredentials converts the kubernetes block to the Kubernetes credentials of the API
+func defineKubernetesCredentials(v interface{}) *sdk.KubernetesCredentials {
+	block := credentialBlock(v)
+	if block == nil {
+		return nil
+	}
+	return &sdk.KubernetesCredentials{
+		Host:                 expandStringPtr(block["host"]),
+		Namespace:            expandStringPtr(block["namespace"]),
+		ServiceAccount:       expandStringPtr(block["service_account"]),
+		Token:                expandStringPtr(block["token"]),
+		ClusterCaCertificate: expandStringPtr(block["cluster_ca_certificate"]),
+	}
+}
+
+// defineCredentials sets the credentials of the identity from the credential blocks
+func defineCredentials(d *schema.ResourceData, identity *sdk.Identity) {
+	identity.Aws = defineAwsCredentials(d.Get(credentialKindAws))
+	identity.Azure = defineAzureCredentials(d.Get(credentialKindAzure))
+	identity.Gcp = defineGcpCredentials(d.Get(credentialKindGcp))
+	identity.Kubernetes = defineKubernetesCredentials(d.Get(credentialKindKubernetes))
+}
+
+// patchCredentials creates the patches of the changed credential blocks, rotating the credentials in place
+func patchCredentials(d *schema.ResourceData) ([]sdk.Patch, error) {
+	converters := map[string]func(v interface{}) interface{}{
+		credentialKindAws:        func(v interface{}) interface{} { return defineAwsCredentials(v) },
+		credentialKindAzure:      func(v interface{}) interface{} { return defineAzureCredentials(v) },
+		credentialKindGcp:        func(v interface{}) interface{} { return defineGcpCredentials(v) },
+		credentialKindKubernetes: func(v interface{}) interface{} { return defineKubernetesCredentials(v) },
+	}
+	var patches []sdk.Patch
+	for _, kind := range credentialKinds {
+		if !d.HasChange(kind) {
+			continue
+		}
+		kindPatches, err := patchFromConvertedResourceData(d, kind, "/"+kind, converters[kind])
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, kindPatches...)
+	}
+	return patches, nil
+}
+
+// readCredentials converts the credentials returned by the API to the credential blocks.
+// The secrets are not returned by the API, so they are kept from the state.
+func readCredentials(d *schema.ResourceData, identity *sdk.Identity) {
+	blocks := map[string]map[string]interface{}{}
+	if identity.Aws != nil {
+		blocks[credentialKindAws] = map[string]interface{}{
+			"access_key_id": flattenStringPtr(identity.Aws.AccessKeyId),
+			"role_arn":      flattenStringPtr(identity.Aws.RoleArn),
+			"external_id":   flattenStringPtr(identity.Aws.ExternalId),
+		}
+	}
+	if identity.Azure != nil {
+		blocks[credentialKindAzure] = map[string]interface{}{
+			"tenant_id":       flattenStringPtr(identity.Azure.TenantId),
+			"client_id":       flattenStringPtr(identity.Azure.ClientId),
+			"subscription_id": flattenStringPtr(identity.Azure.SubscriptionId),
+		}
+	}
+	if identity.Gcp != nil {
+		blocks[credentialKindGcp] = map[string]interface{}{
+			"client_email": flattenStringPtr(identity.Gcp.ClientEmail),
+			"project_id":   flattenStringPtr(identity.Gcp.ProjectId),
+		}
+	}
+	if identity.Kubernetes != nil {
+		blocks[credentialKindKubernetes] = map[string]interface{}{
+			"host":                   flattenStringPtr(identity.Kubernetes.Host),
+			"namespace":              flattenStringPtr(identity.Kubernetes.Namespace),
+			"service_account":        flattenStringPtr(identity.Kubernetes.ServiceAccount),
+			"cluster_ca_certificate": flattenStringPtr(identity.Kubernetes.ClusterCaCertificate),
+		}
+	}
+
+	for _, kind := range credentialKinds {
+		block, ok := blocks[kind]
+		if !ok {
+			_ = d.Set(kind, nil)
+			continue
+		}
+		state := credentialBlock(d.Get(kind))
+		for _, field := range credentialSecretFields[kind] {
+			block[field] = ""
+			if state != nil {
+				block[field] = state[field]
+			}
+		}
+		_ = d.Set(kind, []map[string]interface{}{block})
+	}
+}
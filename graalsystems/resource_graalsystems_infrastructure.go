@@ -0,0 +1,148 @@
+package graalsystems
+
+import (
+	"context"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceGraalSystemsInfrastructure defines the schema for the infrastructure resource
+// An infrastructure is a cloud account and region registered in GraalSystems, on which the jobs and the workspaces run
+func resourceGraalSystemsInfrastructure() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsInfrastructureCreate,
+		ReadContext:   resourceGraalSystemsInfrastructureRead,
+		UpdateContext: resourceGraalSystemsInfrastructureUpdate,
+		DeleteContext: resourceGraalSystemsInfrastructureDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the infrastructure",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description of the infrastructure",
+			},
+			"provider_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The cloud provider of the infrastructure, e.g. `aws`, `azure` or `gcp`",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The region of the cloud provider the infrastructure is deployed in",
+			},
+			"identity_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The id of the identity holding the credentials of the cloud account",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The status of the infrastructure",
+			},
+			"instance_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The names of the instance types available on the infrastructure",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceGraalSystemsInfrastructureCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	name := d.Get("name").(string)
+	description := d.Get("description").(string)
+	provider := d.Get("provider_name").(string)
+	region := d.Get("region").(string)
+	identityId := d.Get("identity_id").(string)
+	infrastructure := &sdk.Infrastructure{
+		Name:        &name,
+		Description: &description,
+		Provider:    &provider,
+		Region:      &region,
+		IdentityId:  &identityId,
+	}
+	result, _, err := apiClient.InfrastructureAPI.CreateInfrastructure(context.Background()).XTenant(meta.tenant).Infrastructure(*infrastructure).Execute()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(*result.Id)
+
+	return resourceGraalSystemsInfrastructureRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsInfrastructureRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	res, _, err := apiClient.InfrastructureAPI.FindInfrastructureById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil {
+		if is404Error(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	_ = d.Set("name", res.Name)
+	_ = d.Set("description", res.Description)
+	_ = d.Set("provider_name", res.Provider)
+	_ = d.Set("region", res.Region)
+	_ = d.Set("identity_id", res.IdentityId)
+	_ = d.Set("status", res.Status)
+	// The instance types are only informative, so the infrastructure is still read when they cannot be listed
+	if instanceTypes, _, err := apiClient.InfrastructureAPI.FindInstanceTypesByInfrastructureId(context.Background(), d.Id()).XTenant(meta.tenant).Execute(); err != nil {
+		l.Warningf("cannot list the instance types of the infrastructure %s: %s", d.Id(), err)
+	} else {
+		var instanceTypeNames []string
+		for _, instanceType := range instanceTypes {
+			instanceTypeNames = append(instanceTypeNames, flattenStringPtr(instanceType.Name))
+		}
+		_ = d.Set("instance_types", flattenSliceString(instanceTypeNames))
+	}
+
+	return nil
+}
+
+func resourceGraalSystemsInfrastructureUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	if patches := patchesFromResourceData(d, "name", "description", "identity_id"); len(patches) > 0 {
+		_, _, err := apiClient.InfrastructureAPI.UpdateInfrastructure(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceGraalSystemsInfrastructureRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsInfrastructureDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	_, err := apiClient.InfrastructureAPI.DeleteInfrastructureById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil && !is404Error(err) {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
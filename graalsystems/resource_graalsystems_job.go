@@ -5,6 +5,7 @@ import (
 	"fmt"
 	sdk "github.com/graalsystems/sdk/go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"slices"
 )
@@ -18,6 +19,10 @@ func resourceGraalSystemsJob() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: customdiff.All(
+			customizeDiffScheduleNextRuns,
+			customizeDiffInstanceType("options.0.instance_type", "project_id", "schedule.0.infrastructure_id"),
+		),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -32,6 +37,7 @@ func resourceGraalSystemsJob() *schema.Resource {
 			"project_id": {
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 				Description: "The project id of the project the job belongs to",
 			},
 			"identity_id": {
@@ -87,7 +93,7 @@ func resourceGraalSystemsJob() *schema.Resource {
 						"instance_type": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "Compute instance type to use for the job. Check which instance types are available for your project",
+							Description: "Compute instance type to use for the job. The `graalsystems_instance_types` data source lists the instance types available for your project",
 						},
 						"type": {
 							Type:        schema.TypeString,
@@ -111,17 +117,67 @@ func resourceGraalSystemsJob() *schema.Resource {
 							Optional:    true,
 							Description: "Python module to execute. Only used if type is `python`",
 						},
+						"main_class_name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Fully qualified name of the main class of the application. Only used if type is `spark`",
+						},
+						"arguments": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "List of arguments passed to the main class. Only used if type is `spark`",
+						},
+						"driver_cores": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Computed:     true,
+							Description:  "Number of cores of the Spark driver. Only used if type is `spark`. Defaults to the API default",
+							ValidateFunc: validatePositiveInt,
+						},
+						"driver_memory": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							Description: "Memory of the Spark driver, e.g. `4g`. Only used if type is `spark`. Defaults to the API default",
+						},
+						"executor_cores": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Computed:     true,
+							Description:  "Number of cores of each Spark executor. Only used if type is `spark`. Defaults to the API default",
+							ValidateFunc: validatePositiveInt,
+						},
+						"executor_memory": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							Description: "Memory of each Spark executor, e.g. `8g`. Only used if type is `spark`. Defaults to the API default",
+						},
+						"num_executors": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Computed:     true,
+							Description:  "Number of Spark executors. Only used if type is `spark`. Defaults to the API default",
+							ValidateFunc: validatePositiveInt,
+						},
+						"conf": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Key value pairs of Spark configuration properties. Only used if type is `spark`. Defaults to the API default",
+						},
 					},
 				},
 			},
 			"secrets": {
 				Type:        schema.TypeList,
 				Optional:    true,
-				Description: "List of secret ids",
+				Description: "List of secret ids, e.g. the `id` of a `graalsystems_secret`",
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
 			"library": {
-				// TODO: Create a resource & data source for libraries it will allow for easy key retrieval of existing libraries
 				Type:        schema.TypeList,
 				Optional:    true,
 				Description: "List of libraries to use for the job run",
@@ -130,7 +186,7 @@ func resourceGraalSystemsJob() *schema.Resource {
 						"type": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Description: "Library type, should be file by default",
+							Description: fmt.Sprintf("Library type. Possible values in %q. Defaults to `file`", libraryTypes),
 							ValidateFunc: func(val any, key string) (warns []string, errs []error) {
 								if !slices.Contains(libraryTypes, val.(string)) {
 									errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, libraryTypes, val))
@@ -177,12 +233,13 @@ func resourceGraalSystemsJob() *schema.Resource {
 						"password": {
 							Type:        schema.TypeString,
 							Optional:    true,
+							Sensitive:   true,
 							Description: "Password to use to connect to git. Only used if type is `git`",
 						},
 						"key": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Id of the library to use in the job. Only used if type is `file`",
+							Optional:    true,
+							Description: "Key of the library to use in the job, e.g. the `key` of a `graalsystems_library`. Only used if type is `file`",
 						},
 						"ref": {
 							Type:        schema.TypeString,
@@ -207,7 +264,7 @@ func resourceGraalSystemsJob() *schema.Resource {
 				Type:        schema.TypeList,
 				MaxItems:    1,
 				Optional:    true,
-				Description: "Schedule mode of the job. Either `once` or `cron`",
+				Description: "Schedule mode of the job. Either `once` or `cron`. The job runs once if not set",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"type": {
@@ -221,15 +278,16 @@ func resourceGraalSystemsJob() *schema.Resource {
 							Required: true,
 						},
 						"cron_expression": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Cron expression of the schedule. Only used if type is `cron`",
-							//TODO: add validate for cron exp ?
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "Cron expression of the schedule. Only used if type is `cron`",
+							ValidateDiagFunc: validateCronExpression(),
 						},
 						"timezone": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Timezone of the schedule. Only used if type is `cron`",
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "IANA timezone of the schedule, e.g. `Europe/Paris`. Only used if type is `cron`",
+							ValidateDiagFunc: validateTimezone(),
 						},
 						"infrastructure_id": {
 							Type:        schema.TypeString,
@@ -244,9 +302,14 @@ func resourceGraalSystemsJob() *schema.Resource {
 					},
 				},
 			},
+			"next_runs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: fmt.Sprintf("The next %d fire times of the schedule, in RFC 3339 format, computed on every refresh. Empty if the schedule type is `once`", scheduleNextRunsCount),
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"notification": notificationSchema(),
 			/* TODO: add the following fields
-			"notifications"
-			"secrets"
 			"metadata"*/
 
 		},
@@ -277,11 +340,13 @@ func resourceGraalSystemsJobCreate(ctx context.Context, d *schema.ResourceData,
 	}
 	currentOptions := defineOptions(opts[0])
 
-	sch := d.Get("schedule").([]interface{})
-	if diagnostics := validateSchedule(sch[0]); diagnostics != nil {
-		return diagnostics
+	var schedule sdk.ISchedule = *sdk.NewRunOnceSchedule()
+	if sch := d.Get("schedule").([]interface{}); len(sch) > 0 {
+		if diagnostics := validateSchedule(sch[0]); diagnostics != nil {
+			return diagnostics
+		}
+		schedule = defineSchedule(sch[0])
 	}
-	schedule := defineSchedule(sch[0])
 
 	libs := d.Get("library").([]interface{})
 	if diagnostics := validateLibraries(libs); diagnostics != nil {
@@ -289,6 +354,12 @@ func resourceGraalSystemsJobCreate(ctx context.Context, d *schema.ResourceData,
 	}
 	libraries := defineLibraries(libs)
 
+	notificationBlocks := d.Get("notification").([]interface{})
+	if diagnostics := validateNotifications(notificationBlocks); diagnostics != nil {
+		return diagnostics
+	}
+	notifications := defineNotifications(notificationBlocks)
+
 	job := &sdk.Job{
 		Name:           &name,
 		Description:    &description,
@@ -301,6 +372,7 @@ func resourceGraalSystemsJobCreate(ctx context.Context, d *schema.ResourceData,
 		Labels:         &labels,
 		Schedule:       &schedule,
 		Libraries:      libraries,
+		Notifications:  &notifications,
 	}
 	result, response, err := apiClient.ProjectAPI.CreateJobForProject(context.Background(), projectId).XTenant(meta.tenant).Job(*job).Execute()
 	if err != nil {
@@ -331,6 +403,39 @@ func resourceGraalSystemsJobRead(ctx context.Context, d *schema.ResourceData, m
 
 	_ = d.Set("name", res.Name)
 	_ = d.Set("description", res.Description)
+	_ = d.Set("project_id", res.ProjectId)
+	_ = d.Set("identity_id", res.IdentityId)
+	_ = d.Set("timeout_seconds", res.TimeoutSeconds)
+	_ = d.Set("max_retries", res.MaxRetries)
+	_ = d.Set("secrets", res.Secrets)
+	_ = d.Set("parameters", res.Parameters)
+	_ = d.Set("labels", flattenStringMapPtr(res.Labels))
+
+	if res.Options != nil {
+		options, err := readOptions(*res.Options)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		_ = d.Set("options", options)
+	}
+	libraries, err := readLibraries(res.Libraries, d.Get("library").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_ = d.Set("library", libraries)
+	_ = d.Set("notification", readNotifications(res.Notifications))
+	if res.Schedule != nil {
+		schedule, err := readSchedule(*res.Schedule)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		schedule = readJobSchedule(schedule, d.Get("schedule").([]interface{}))
+		_ = d.Set("schedule", schedule)
+		_ = d.Set("next_runs", readNextRuns(schedule))
+	} else {
+		_ = d.Set("schedule", nil)
+		_ = d.Set("next_runs", nil)
+	}
 
 	return nil
 }
@@ -338,19 +443,14 @@ func resourceGraalSystemsJobRead(ctx context.Context, d *schema.ResourceData, m
 func resourceGraalSystemsJobUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
-	//TODO: update when other parameters are updated
-	if d.HasChange("name") {
-		path := "/name"
 
-		value := d.Get("name").(string)
+	patches, diagnostics := jobPatches(d)
+	if diagnostics != nil {
+		return diagnostics
+	}
 
-		patch := &sdk.Patch{
-			Op:    nil,
-			Path:  &path,
-			Value: &value,
-		}
-		patchs := &[]sdk.Patch{*patch}
-		_, _, err := apiClient.JobAPI.UpdateJob(context.Background(), d.Id()).XTenant(meta.tenant).Patch(*patchs).Execute()
+	if len(patches) > 0 {
+		_, _, err := apiClient.JobAPI.UpdateJob(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
 		if err != nil {
 			return diag.FromErr(err)
 		}
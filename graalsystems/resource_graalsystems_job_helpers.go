@@ -1,10 +1,19 @@
 package graalsystems
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/robfig/cron/v3"
+	"slices"
+	"strings"
+	"time"
+	// Embed the IANA time zone database, so that timezones are validated the same way on every platform
+	_ "time/tzdata"
 )
 
 const (
@@ -15,15 +24,91 @@ const (
 const (
 	optionTypeBash   = "bash"
 	optionTypePython = "python"
+	optionTypeSpark  = "spark"
 )
 
 const (
-	libraryTypeFile = "file"
+	libraryTypeFile  = "file"
+	libraryTypePypi  = "pypi"
+	libraryTypeMaven = "maven"
+	libraryTypeGit   = "git"
+	libraryTypeCran  = "cran"
 )
 
 var scheduleTypes = []string{scheduleTypeOnce, scheduleTypeCron}
-var optionsTypes = []string{optionTypeBash, optionTypePython}
-var libraryTypes = []string{libraryTypeFile}
+var optionsTypes = []string{optionTypeBash, optionTypePython, optionTypeSpark}
+var libraryTypes = []string{libraryTypeFile, libraryTypePypi, libraryTypeMaven, libraryTypeGit, libraryTypeCran}
+
+// libraryFields lists the attributes of the library block used by each library type
+var libraryFields = map[string][]string{
+	libraryTypeFile:  {"key"},
+	libraryTypePypi:  {"dep"},
+	libraryTypeMaven: {"dependency", "repo"},
+	libraryTypeGit:   {"url", "path", "revision", "username", "password"},
+	libraryTypeCran:  {"ref"},
+}
+
+// libraryRequiredFields lists the attributes of the library block required by each library type
+var libraryRequiredFields = map[string][]string{
+	libraryTypeFile:  {"key"},
+	libraryTypePypi:  {"dep"},
+	libraryTypeMaven: {"dependency"},
+	libraryTypeGit:   {"url"},
+	libraryTypeCran:  {"ref"},
+}
+
+// jobPatchAttributes lists the job attributes located at the same path in the job JSON representation
+var jobPatchAttributes = []string{"name", "description", "identity_id", "timeout_seconds", "max_retries", "secrets", "parameters", "labels"}
+
+// jobPatches creates the patches of every changed attribute of the job
+func jobPatches(d *schema.ResourceData) ([]sdk.Patch, diag.Diagnostics) {
+	patches := patchesFromResourceData(d, jobPatchAttributes...)
+
+	if d.HasChange("options") {
+		if diagnostics := validateOptions(d.Get("options").([]interface{})[0]); diagnostics != nil {
+			return nil, diagnostics
+		}
+		optionPatches, err := patchFromConvertedResourceData(d, "options", "/options", func(v interface{}) interface{} {
+			if opts := v.([]interface{}); len(opts) > 0 {
+				return defineOptions(opts[0])
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		patches = append(patches, optionPatches...)
+	}
+
+	if d.HasChange("library") {
+		if diagnostics := validateLibraries(d.Get("library").([]interface{})); diagnostics != nil {
+			return nil, diagnostics
+		}
+		libraryPatches, err := patchFromConvertedResourceData(d, "library", "/libraries", func(v interface{}) interface{} {
+			return defineLibraries(v.([]interface{}))
+		})
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		patches = append(patches, libraryPatches...)
+	}
+
+	if d.HasChange("schedule") {
+		schedulePatches, diagnostics := patchSchedule(d)
+		if diagnostics != nil {
+			return nil, diagnostics
+		}
+		patches = append(patches, schedulePatches...)
+	}
+
+	notificationPatches, diagnostics := patchNotifications(d)
+	if diagnostics != nil {
+		return nil, diagnostics
+	}
+	patches = append(patches, notificationPatches...)
+
+	return patches, nil
+}
 
 func validateOptions(input interface{}) diag.Diagnostics {
 	var opts sdk.Options
@@ -56,6 +141,11 @@ func validateOptions(input interface{}) diag.Diagnostics {
 			return diag.FromErr(fmt.Errorf("module parameter is required for options type %s", optionTypePython))
 		}
 	}
+	if *opts.Type == optionTypeSpark {
+		if mainClassName, _ := input.(map[string]interface{})["main_class_name"].(string); mainClassName == "" {
+			return diag.FromErr(fmt.Errorf("main_class_name parameter is required for options type %s", optionTypeSpark))
+		}
+	}
 	return nil
 }
 
@@ -88,9 +178,50 @@ func defineOptions(input interface{}) sdk.IOptions {
 		}
 		return opt
 	}
+	if *opts.Type == optionTypeSpark {
+		return defineSparkOptions(opts, input.(map[string]interface{}))
+	}
 	return nil
 }
 
+// defineSparkOptions converts the options block to spark options. Unset sizing attributes are left to the API defaults,
+// which are read back into the state as computed values.
+func defineSparkOptions(opts sdk.Options, input map[string]interface{}) sdk.SparkOptions {
+	mainClassName := input["main_class_name"].(string)
+	opt := sdk.SparkOptions{
+		Options:       opts,
+		MainClassName: &mainClassName,
+		Arguments:     toStringList(input["arguments"].([]interface{})),
+	}
+	if driverCores := int32(input["driver_cores"].(int)); driverCores > 0 {
+		opt.DriverCores = &driverCores
+	}
+	if driverMemory := input["driver_memory"].(string); driverMemory != "" {
+		opt.DriverMemory = &driverMemory
+	}
+	if executorCores := int32(input["executor_cores"].(int)); executorCores > 0 {
+		opt.ExecutorCores = &executorCores
+	}
+	if executorMemory := input["executor_memory"].(string); executorMemory != "" {
+		opt.ExecutorMemory = &executorMemory
+	}
+	if numExecutors := int32(input["num_executors"].(int)); numExecutors > 0 {
+		opt.NumExecutors = &numExecutors
+	}
+	if conf := toStringMap(input["conf"].(map[string]interface{})); len(conf) > 0 {
+		opt.Conf = &conf
+	}
+	return opt
+}
+
+// validatePositiveInt validates that an optional integer attribute is strictly positive
+func validatePositiveInt(val any, key string) (warns []string, errs []error) {
+	if v := val.(int); v <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive integer, got: %d", key, v))
+	}
+	return
+}
+
 func validateSchedule(input interface{}) diag.Diagnostics {
 	convertedInput := toStringMap(input.(map[string]interface{}))
 
@@ -122,52 +253,346 @@ func validateSchedule(input interface{}) diag.Diagnostics {
 	return nil
 }
 
+// defineSchedule converts the schedule block to the matching polymorphic schedule.
+// The type is always set, as the API relies on it to deserialize the schedule.
 func defineSchedule(input interface{}) sdk.ISchedule {
 	convertedInput := toStringMap(input.(map[string]interface{}))
 
-	if convertedInput["type"] == scheduleTypeCron {
-		var sch sdk.CronSchedule
-		bytes, err := json.Marshal(convertedInput)
-		if err != nil {
-			fmt.Println("Cron schedule marshall error:", err)
+	scheduleType := convertedInput["type"]
+	if scheduleType == scheduleTypeCron {
+		cronExpression := convertedInput["cron_expression"]
+		timezone := convertedInput["timezone"]
+		infrastructureId := convertedInput["infrastructure_id"]
+		sch := sdk.CronSchedule{
+			Schedule:         sdk.Schedule{Type: &scheduleType},
+			CronExpression:   &cronExpression,
+			Timezone:         &timezone,
+			InfrastructureId: &infrastructureId,
 		}
-		err = json.Unmarshal(bytes, &sch)
-		if err != nil {
-			fmt.Println("Cron schedule unmarshall error:", err)
+		if deviceId := convertedInput["device_id"]; deviceId != "" {
+			sch.DeviceId = &deviceId
 		}
 		return sch
 	}
-	return *sdk.NewRunOnceSchedule()
+	sch := *sdk.NewRunOnceSchedule()
+	scheduleType = scheduleTypeOnce
+	sch.Type = &scheduleType
+	return sch
+}
+
+// patchSchedule creates the patches of the change of the schedule block, for both jobs and workflows.
+// Switching between `once` and `cron` replaces the whole schedule, while editing a cron schedule only patches the changed fields.
+func patchSchedule(d *schema.ResourceData) ([]sdk.Patch, diag.Diagnostics) {
+	if sch := d.Get("schedule").([]interface{}); len(sch) > 0 {
+		if diagnostics := validateSchedule(sch[0]); diagnostics != nil {
+			return nil, diagnostics
+		}
+	}
+	patches, err := patchFromConvertedResourceData(d, "schedule", "/schedule", func(v interface{}) interface{} {
+		if sch := v.([]interface{}); len(sch) > 0 {
+			return defineSchedule(sch[0])
+		}
+		// Without schedule block, the job runs once as when it is created
+		return defineSchedule(map[string]interface{}{"type": scheduleTypeOnce})
+	})
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return patches, nil
+}
+
+// readJobSchedule returns the schedule block of the job read from the API.
+// The jobs without schedule block are created with a run once schedule, which is kept out of the state unless the block is configured.
+func readJobSchedule(schedule []map[string]string, current []interface{}) []map[string]string {
+	if len(current) == 0 && len(schedule) == 1 && schedule[0]["type"] == scheduleTypeOnce {
+		return nil
+	}
+	return schedule
 }
 
 func validateLibraries(input []interface{}) diag.Diagnostics {
-	for _, lib := range input {
+	for i, lib := range input {
 		convertedInput := toStringMap(lib.(map[string]interface{}))
-		if convertedInput["type"] == libraryTypeFile {
-			if vKey := convertedInput["key"]; len(vKey) == 0 {
-				return diag.FromErr(fmt.Errorf("key is required for library type %s", libraryTypeFile))
+		libraryType := convertedInput["type"]
+		fields := libraryFields[libraryType]
+		for _, field := range libraryRequiredFields[libraryType] {
+			if len(convertedInput[field]) == 0 {
+				return diag.FromErr(fmt.Errorf("%s is required for library type %s (library %d)", field, libraryType, i))
+			}
+		}
+		for _, fieldsOfType := range libraryFields {
+			for _, field := range fieldsOfType {
+				if len(convertedInput[field]) > 0 && !slices.Contains(fields, field) {
+					return diag.FromErr(fmt.Errorf("%s is not allowed for library type %s (library %d)", field, libraryType, i))
+				}
 			}
 		}
 	}
 	return nil
 }
 
+// defineLibraries converts the library blocks to the matching polymorphic libraries.
+// The type is always set, as the API relies on it to deserialize the libraries.
 func defineLibraries(input []interface{}) []sdk.ILibrary {
 	var libs []sdk.ILibrary
 	for _, lib := range input {
 		convertedInput := toStringMap(lib.(map[string]interface{}))
-		if convertedInput["type"] == libraryTypeFile {
-			var lib sdk.FileLibrary
-			bytes, err := json.Marshal(convertedInput)
-			if err != nil {
-				fmt.Println("file library marshall error:", err)
+		libraryType := convertedInput["type"]
+		library := sdk.Library{Type: &libraryType}
+		switch libraryType {
+		case libraryTypeFile:
+			libs = append(libs, sdk.FileLibrary{
+				Library: library,
+				Key:     expandStringPtr(convertedInput["key"]),
+			})
+		case libraryTypePypi:
+			libs = append(libs, sdk.PypiLibrary{
+				Library: library,
+				Dep:     expandStringPtr(convertedInput["dep"]),
+			})
+		case libraryTypeMaven:
+			libs = append(libs, sdk.MavenLibrary{
+				Library:    library,
+				Repo:       expandStringPtr(convertedInput["repo"]),
+				Dependency: expandStringPtr(convertedInput["dependency"]),
+			})
+		case libraryTypeGit:
+			libs = append(libs, sdk.GitLibrary{
+				Library:  library,
+				Url:      expandStringPtr(convertedInput["url"]),
+				Path:     expandStringPtr(convertedInput["path"]),
+				Revision: expandStringPtr(convertedInput["revision"]),
+				Username: expandStringPtr(convertedInput["username"]),
+				Password: expandStringPtr(convertedInput["password"]),
+			})
+		case libraryTypeCran:
+			libs = append(libs, sdk.CranLibrary{
+				Library: library,
+				Ref:     expandStringPtr(convertedInput["ref"]),
+			})
+		}
+	}
+	return libs
+}
+
+// readOptions converts the polymorphic options returned by the API to the options block of the job schema
+func readOptions(options sdk.IOptions) ([]map[string]interface{}, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	// Deserialize the options into the abstract type
+	var opts sdk.Options
+	optBytes, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("options read marshall error: %s", err)
+	}
+	if err := json.Unmarshal(optBytes, &opts); err != nil {
+		return nil, fmt.Errorf("options read unmarshall error: %s", err)
+	}
+
+	opt := map[string]interface{}{
+		"type":          flattenStringPtr(opts.Type),
+		"docker_image":  flattenStringPtr(opts.DockerImage),
+		"instance_type": flattenStringPtr(opts.InstanceType),
+		"env":           flattenStringMapPtr(opts.Env),
+	}
+	// Then, depending on the type, we can deserialize it into the correct type
+	switch flattenStringPtr(opts.Type) {
+	case optionTypeBash:
+		var bash sdk.BashOptions
+		if err := json.Unmarshal(optBytes, &bash); err != nil {
+			return nil, fmt.Errorf("bash options read unmarshall error: %s", err)
+		}
+		opt["lines"] = bash.Lines
+	case optionTypePython:
+		var python sdk.PythonOptions
+		if err := json.Unmarshal(optBytes, &python); err != nil {
+			return nil, fmt.Errorf("python options read unmarshall error: %s", err)
+		}
+		opt["module"] = flattenStringPtr(python.Module)
+	case optionTypeSpark:
+		var spark sdk.SparkOptions
+		if err := json.Unmarshal(optBytes, &spark); err != nil {
+			return nil, fmt.Errorf("spark options read unmarshall error: %s", err)
+		}
+		opt["main_class_name"] = flattenStringPtr(spark.MainClassName)
+		opt["arguments"] = spark.Arguments
+		opt["driver_cores"] = flattenInt32Ptr(spark.DriverCores)
+		opt["driver_memory"] = flattenStringPtr(spark.DriverMemory)
+		opt["executor_cores"] = flattenInt32Ptr(spark.ExecutorCores)
+		opt["executor_memory"] = flattenStringPtr(spark.ExecutorMemory)
+		opt["num_executors"] = flattenInt32Ptr(spark.NumExecutors)
+		opt["conf"] = flattenStringMapPtr(spark.Conf)
+	default:
+		return nil, fmt.Errorf("options type %s is not yet supported", flattenStringPtr(opts.Type))
+	}
+	return []map[string]interface{}{opt}, nil
+}
+
+// readLibraries converts the polymorphic libraries returned by the API to the library blocks of the job schema.
+// The API does not return git passwords, so they are kept from the current libraries when the library did not change.
+func readLibraries(libraries []sdk.ILibrary, current []interface{}) ([]map[string]interface{}, error) {
+	var libs []map[string]interface{}
+	for i, library := range libraries {
+		// Deserialize the library into the abstract type
+		var sdkLibrary sdk.Library
+		libBytes, err := json.Marshal(library)
+		if err != nil {
+			return nil, fmt.Errorf("library read marshall error: %s", err)
+		}
+		if err := json.Unmarshal(libBytes, &sdkLibrary); err != nil {
+			return nil, fmt.Errorf("library read unmarshall error: %s", err)
+		}
+		// Then, depending on the type, we can deserialize it into the correct type
+		lib := map[string]interface{}{"type": flattenStringPtr(sdkLibrary.Type)}
+		switch flattenStringPtr(sdkLibrary.Type) {
+		case libraryTypeFile:
+			var file sdk.FileLibrary
+			if err := json.Unmarshal(libBytes, &file); err != nil {
+				return nil, fmt.Errorf("file library read unmarshall error: %s", err)
+			}
+			lib["key"] = flattenStringPtr(file.Key)
+		case libraryTypePypi:
+			var pypi sdk.PypiLibrary
+			if err := json.Unmarshal(libBytes, &pypi); err != nil {
+				return nil, fmt.Errorf("pypi library read unmarshall error: %s", err)
+			}
+			lib["dep"] = flattenStringPtr(pypi.Dep)
+		case libraryTypeMaven:
+			var maven sdk.MavenLibrary
+			if err := json.Unmarshal(libBytes, &maven); err != nil {
+				return nil, fmt.Errorf("maven library read unmarshall error: %s", err)
+			}
+			lib["repo"] = flattenStringPtr(maven.Repo)
+			lib["dependency"] = flattenStringPtr(maven.Dependency)
+		case libraryTypeGit:
+			var git sdk.GitLibrary
+			if err := json.Unmarshal(libBytes, &git); err != nil {
+				return nil, fmt.Errorf("git library read unmarshall error: %s", err)
 			}
-			err = json.Unmarshal(bytes, &lib)
-			if err != nil {
-				fmt.Println("file library unmarshall error:", err)
+			lib["url"] = flattenStringPtr(git.Url)
+			lib["path"] = flattenStringPtr(git.Path)
+			lib["revision"] = flattenStringPtr(git.Revision)
+			lib["username"] = flattenStringPtr(git.Username)
+			lib["password"] = flattenStringPtr(git.Password)
+			if git.Password == nil && i < len(current) && current[i] != nil {
+				if previous := current[i].(map[string]interface{}); previous["type"] == libraryTypeGit && previous["url"] == lib["url"] {
+					lib["password"] = previous["password"]
+				}
 			}
-			libs = append(libs, lib)
+		case libraryTypeCran:
+			var cran sdk.CranLibrary
+			if err := json.Unmarshal(libBytes, &cran); err != nil {
+				return nil, fmt.Errorf("cran library read unmarshall error: %s", err)
+			}
+			lib["ref"] = flattenStringPtr(cran.Ref)
+		default:
+			return nil, fmt.Errorf("library type %s is not yet supported", flattenStringPtr(sdkLibrary.Type))
 		}
+		libs = append(libs, lib)
 	}
-	return libs
+	return libs, nil
+}
+
+// scheduleNextRunsCount is the number of fire times previewed by the next_runs attribute
+const scheduleNextRunsCount = 5
+
+// cronParser parses the cron dialect accepted by GraalSystems: the 5 standard fields (minute, hour, day of month, month
+// and day of week, with lists, ranges, steps and names) or a predefined schedule such as @daily.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseCronExpression parses a cron expression of a schedule
+func parseCronExpression(expression string) (cron.Schedule, error) {
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return nil, fmt.Errorf("the timezone must be set with the timezone attribute, not in the cron expression")
+	}
+	if strings.HasPrefix(expression, "@every") {
+		return nil, fmt.Errorf("@every intervals are not supported, use a cron expression instead")
+	}
+	return cronParser.Parse(expression)
+}
+
+// loadTimezone loads a timezone from the IANA time zone database
+func loadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" || timezone == "Local" {
+		return nil, fmt.Errorf("%q is not an IANA time zone name, e.g. Europe/Paris", timezone)
+	}
+	return time.LoadLocation(timezone)
+}
+
+// validateCronExpression validates the cron expression of a schedule at plan time
+func validateCronExpression() schema.SchemaValidateDiagFunc {
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		if _, err := parseCronExpression(i.(string)); err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid cron expression",
+				Detail:        fmt.Sprintf("%q is not a valid cron expression: %s", i, err),
+				AttributePath: path,
+			}}
+		}
+		return nil
+	}
+}
+
+// validateTimezone validates the timezone of a schedule at plan time
+func validateTimezone() schema.SchemaValidateDiagFunc {
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		if _, err := loadTimezone(i.(string)); err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid timezone",
+				Detail:        fmt.Sprintf("%q is not a valid IANA time zone: %s", i, err),
+				AttributePath: path,
+			}}
+		}
+		return nil
+	}
+}
+
+// scheduleNextRuns returns the next fire times after from of a cron schedule, in RFC 3339 format.
+// Schedules run once have no next runs.
+func scheduleNextRuns(scheduleType string, cronExpression string, timezone string, from time.Time) ([]string, error) {
+	if scheduleType != scheduleTypeCron {
+		return []string{}, nil
+	}
+	sch, err := parseCronExpression(cronExpression)
+	if err != nil {
+		return nil, err
+	}
+	location, err := loadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	nextRuns := make([]string, 0, scheduleNextRunsCount)
+	next := from.In(location)
+	for i := 0; i < scheduleNextRunsCount; i++ {
+		next = sch.Next(next)
+		nextRuns = append(nextRuns, next.Format(time.RFC3339))
+	}
+	return nextRuns, nil
+}
+
+// readNextRuns returns the next fire times of a schedule read from the API, computed again on every refresh.
+// The API may accept expressions this provider cannot parse, in which case no preview is available.
+func readNextRuns(schedule []map[string]string) []string {
+	if len(schedule) == 0 {
+		return []string{}
+	}
+	nextRuns, err := scheduleNextRuns(schedule[0]["type"], schedule[0]["cron_expression"], schedule[0]["timezone"], time.Now())
+	if err != nil {
+		return nil
+	}
+	return nextRuns
+}
+
+// customizeDiffScheduleNextRuns marks the next fire times unknown when the schedule changes.
+// They depend on the current time, so they are never planned: the plan and the apply would compute different values.
+func customizeDiffScheduleNextRuns(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.HasChange("schedule") {
+		return nil
+	}
+	return d.SetNewComputed("next_runs")
 }
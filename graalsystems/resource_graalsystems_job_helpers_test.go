@@ -0,0 +1,247 @@
+package graalsystems
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	for _, expression := range []string{"0 0 * * *", "0 0 1 1 *", "*/15 8-18 * * MON-FRI", "30 2 1,15 * *", "@daily", "@hourly"} {
+		_, err := parseCronExpression(expression)
+		assert.Nil(t, err, expression)
+	}
+	for _, expression := range []string{"", "0 0 * *", "0 0 0 * * *", "61 * * * *", "* * * * FOO", "@every 1h", "TZ=Europe/Paris 0 0 * * *"} {
+		_, err := parseCronExpression(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+func TestLoadTimezone(t *testing.T) {
+	for _, timezone := range []string{"UTC", "Europe/Paris", "America/New_York"} {
+		_, err := loadTimezone(timezone)
+		assert.Nil(t, err, timezone)
+	}
+	for _, timezone := range []string{"", "Local", "Europe/Pariss", "CEST"} {
+		_, err := loadTimezone(timezone)
+		assert.NotNil(t, err, timezone)
+	}
+}
+
+func TestValidateCronExpression_AttributePath(t *testing.T) {
+	path := cty.GetAttrPath("schedule").IndexInt(0).GetAttr("cron_expression")
+
+	assert.Nil(t, validateCronExpression()("0 0 * * *", path))
+
+	diagnostics := validateCronExpression()("0 0 * *", path)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, path, diagnostics[0].AttributePath)
+}
+
+func TestScheduleNextRuns(t *testing.T) {
+	from := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	nextRuns, err := scheduleNextRuns(scheduleTypeCron, "0 0 * * *", "Europe/Paris", from)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"2024-01-02T00:00:00+01:00",
+		"2024-01-03T00:00:00+01:00",
+		"2024-01-04T00:00:00+01:00",
+		"2024-01-05T00:00:00+01:00",
+		"2024-01-06T00:00:00+01:00",
+	}, nextRuns)
+
+	nextRuns, err = scheduleNextRuns(scheduleTypeOnce, "", "", from)
+	assert.Nil(t, err)
+	assert.Empty(t, nextRuns)
+
+	_, err = scheduleNextRuns(scheduleTypeCron, "0 0 * * *", "Mars/Olympus", from)
+	assert.NotNil(t, err)
+}
+
+// libraryBlock returns a library block as read from the terraform schema, every attribute being set
+func libraryBlock(values map[string]interface{}) map[string]interface{} {
+	block := map[string]interface{}{
+		"type": "", "key": "", "dep": "", "repo": "", "dependency": "", "url": "", "path": "", "revision": "", "username": "", "password": "", "ref": "",
+	}
+	for k, v := range values {
+		block[k] = v
+	}
+	return block
+}
+
+func TestValidateLibraries(t *testing.T) {
+	assert.Nil(t, validateLibraries([]interface{}{
+		libraryBlock(map[string]interface{}{"type": "file", "key": "xxxxxxxx"}),
+		libraryBlock(map[string]interface{}{"type": "pypi", "dep": "numpy==1.26.0"}),
+		libraryBlock(map[string]interface{}{"type": "maven", "dependency": "org.acme:lib:1.0.0", "repo": "https://repo.acme.org"}),
+		libraryBlock(map[string]interface{}{"type": "git", "url": "https://github.com/acme/lib.git", "username": "me", "password": "secret"}),
+		libraryBlock(map[string]interface{}{"type": "cran", "ref": "dplyr"}),
+	}))
+
+	assert.NotNil(t, validateLibraries([]interface{}{libraryBlock(map[string]interface{}{"type": "pypi"})}))
+	assert.NotNil(t, validateLibraries([]interface{}{libraryBlock(map[string]interface{}{"type": "git", "path": "lib"})}))
+	assert.NotNil(t, validateLibraries([]interface{}{libraryBlock(map[string]interface{}{"type": "file", "key": "xxxxxxxx", "dep": "numpy"})}))
+}
+
+func TestDefineLibraries(t *testing.T) {
+	libraries := defineLibraries([]interface{}{
+		libraryBlock(map[string]interface{}{"type": "pypi", "dep": "numpy==1.26.0"}),
+		libraryBlock(map[string]interface{}{"type": "git", "url": "https://github.com/acme/lib.git", "password": "secret"}),
+	})
+	assert.Len(t, libraries, 2)
+
+	read, err := readLibraries(libraries, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "pypi", read[0]["type"])
+	assert.Equal(t, "numpy==1.26.0", read[0]["dep"])
+	assert.Equal(t, "git", read[1]["type"])
+	assert.Equal(t, "https://github.com/acme/lib.git", read[1]["url"])
+	assert.Equal(t, "secret", read[1]["password"])
+}
+
+func TestReadJobSchedule(t *testing.T) {
+	once := []map[string]string{{"type": "once"}}
+	cron := []map[string]string{{"type": "cron", "cron_expression": "0 0 * * *"}}
+
+	// The implicit run once schedule is kept out of the state
+	assert.Nil(t, readJobSchedule(once, nil))
+	// It is kept when configured, as any other schedule
+	assert.Equal(t, once, readJobSchedule(once, []interface{}{map[string]interface{}{"type": "once"}}))
+	assert.Equal(t, cron, readJobSchedule(cron, nil))
+}
+
+func TestJobWithoutSchedule_NoDiff(t *testing.T) {
+	jobSchema := resourceGraalSystemsJob().Schema
+	config := map[string]interface{}{
+		"name":       "job",
+		"project_id": "project",
+		"options":    []interface{}{map[string]interface{}{"type": "bash", "lines": []interface{}{"echo"}, "instance_type": "Standard_D2s_v3"}},
+	}
+
+	// The job is read back after its creation with the implicit run once schedule
+	d := schema.TestResourceDataRaw(t, jobSchema, config)
+	d.SetId("job")
+	schedule, err := readSchedule(defineSchedule(map[string]interface{}{"type": "once"}))
+	assert.Nil(t, err)
+	assert.Nil(t, d.Set("schedule", readJobSchedule(schedule, d.Get("schedule").([]interface{}))))
+
+	diff, err := schema.InternalMap(jobSchema).Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), nil, nil, true)
+	assert.Nil(t, err)
+	if diff != nil {
+		for attribute := range diff.Attributes {
+			assert.NotContains(t, attribute, "schedule")
+		}
+	}
+}
+
+func TestReadNextRuns(t *testing.T) {
+	nextRuns := readNextRuns([]map[string]string{{"type": "cron", "cron_expression": "0 0 * * *", "timezone": "UTC"}})
+	assert.Len(t, nextRuns, scheduleNextRunsCount)
+	first, err := time.Parse(time.RFC3339, nextRuns[0])
+	assert.Nil(t, err)
+	assert.True(t, first.After(time.Now()))
+
+	assert.Empty(t, readNextRuns([]map[string]string{{"type": "once"}}))
+	assert.Empty(t, readNextRuns(nil))
+}
+
+// sparkOptionsBlock returns a spark options block, with the zero values terraform reports for the unset attributes
+func sparkOptionsBlock(attributes map[string]interface{}) map[string]interface{} {
+	block := map[string]interface{}{
+		"type": "spark", "docker_image": "spark:3.5", "instance_type": "Standard_D2s_v3", "env": map[string]interface{}{},
+		"lines": []interface{}{}, "module": "", "main_class_name": "org.acme.Main", "arguments": []interface{}{},
+		"driver_cores": 0, "driver_memory": "", "executor_cores": 0, "executor_memory": "", "num_executors": 0, "conf": map[string]interface{}{},
+	}
+	for k, v := range attributes {
+		block[k] = v
+	}
+	return block
+}
+
+func TestDefineOptions_Spark(t *testing.T) {
+	options := defineOptions(sparkOptionsBlock(map[string]interface{}{
+		"arguments":       []interface{}{"--date", "2024-01-01"},
+		"driver_cores":    1,
+		"executor_memory": "4g",
+		"num_executors":   3,
+		"conf":            map[string]interface{}{"spark.sql.shuffle.partitions": "200"},
+	}))
+	spark, ok := options.(sdk.SparkOptions)
+	assert.True(t, ok)
+	assert.Equal(t, "spark", *spark.Type)
+	assert.Equal(t, "org.acme.Main", *spark.MainClassName)
+	assert.Equal(t, []string{"--date", "2024-01-01"}, spark.Arguments)
+	assert.Equal(t, int32(1), *spark.DriverCores)
+	assert.Equal(t, "4g", *spark.ExecutorMemory)
+	assert.Equal(t, int32(3), *spark.NumExecutors)
+	assert.Equal(t, map[string]string{"spark.sql.shuffle.partitions": "200"}, *spark.Conf)
+
+	// The unset sizing attributes are left to the API defaults
+	spark = defineOptions(sparkOptionsBlock(nil)).(sdk.SparkOptions)
+	assert.Nil(t, spark.DriverCores)
+	assert.Nil(t, spark.DriverMemory)
+	assert.Nil(t, spark.ExecutorCores)
+	assert.Nil(t, spark.ExecutorMemory)
+	assert.Nil(t, spark.NumExecutors)
+	assert.Nil(t, spark.Conf)
+
+	assert.NotNil(t, validateOptions(sparkOptionsBlock(map[string]interface{}{"main_class_name": ""})))
+	assert.Nil(t, validateOptions(sparkOptionsBlock(nil)))
+}
+
+func TestReadOptions_Spark(t *testing.T) {
+	spark := defineOptions(sparkOptionsBlock(map[string]interface{}{"executor_memory": "4g"})).(sdk.SparkOptions)
+	// The API fills the unset sizing attributes with its defaults
+	driverCores, numExecutors := int32(1), int32(2)
+	spark.DriverCores = &driverCores
+	spark.NumExecutors = &numExecutors
+
+	options, err := readOptions(spark)
+	assert.Nil(t, err)
+	assert.Len(t, options, 1)
+	assert.Equal(t, "spark", options[0]["type"])
+	assert.Equal(t, "org.acme.Main", options[0]["main_class_name"])
+	assert.Equal(t, 1, options[0]["driver_cores"])
+	assert.Equal(t, "", options[0]["driver_memory"])
+	assert.Equal(t, "4g", options[0]["executor_memory"])
+	assert.Equal(t, 2, options[0]["num_executors"])
+	assert.Equal(t, map[string]string{}, options[0]["conf"])
+}
+
+func TestSparkJobWithApiDefaults_NoDiff(t *testing.T) {
+	jobSchema := resourceGraalSystemsJob().Schema
+	config := map[string]interface{}{
+		"name":       "job",
+		"project_id": "project",
+		"options": []interface{}{map[string]interface{}{
+			"type": "spark", "docker_image": "spark:3.5", "instance_type": "Standard_D2s_v3", "main_class_name": "org.acme.Main",
+		}},
+	}
+
+	// The job is read back with the sizing defaults of the API
+	d := schema.TestResourceDataRaw(t, jobSchema, config)
+	d.SetId("job")
+	driverCores, numExecutors, executorMemory := int32(1), int32(2), "4g"
+	spark := defineOptions(d.Get("options").([]interface{})[0]).(sdk.SparkOptions)
+	spark.DriverCores = &driverCores
+	spark.NumExecutors = &numExecutors
+	spark.ExecutorMemory = &executorMemory
+	options, err := readOptions(spark)
+	assert.Nil(t, err)
+	assert.Nil(t, d.Set("options", options))
+
+	diff, err := schema.InternalMap(jobSchema).Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), nil, nil, true)
+	assert.Nil(t, err)
+	if diff != nil {
+		for attribute := range diff.Attributes {
+			assert.NotContains(t, attribute, "options")
+		}
+	}
+}
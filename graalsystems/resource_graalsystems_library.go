@@ -0,0 +1,185 @@
+package graalsystems
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceGraalSystemsLibrary defines the schema for the library resource
+// A library is a file (jar, wheel, zip...) uploaded to GraalSystems, that jobs reference by its key
+func resourceGraalSystemsLibrary() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsLibraryCreate,
+		ReadContext:   resourceGraalSystemsLibraryRead,
+		UpdateContext: resourceGraalSystemsLibraryUpdate,
+		DeleteContext: resourceGraalSystemsLibraryDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: customizeDiffLibraryContentHash,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the library",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description of the library",
+			},
+			"source": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The path of the local file to upload (jar, wheel, zip...)",
+			},
+			"content_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SHA-256 hash of the uploaded file. A change of the file content uploads a new library",
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The key of the library, to reference in the `library` blocks of type `file` of the jobs",
+			},
+		},
+	}
+}
+
+// fileSha256 returns the hex encoded SHA-256 hash of the content of the file at path
+func fileSha256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// customizeDiffLibraryContentHash uploads a new library when the content of the source file changes.
+// The source may not exist yet at plan time, e.g. a build artifact produced during the same apply: its hash is then unknown,
+// and an existing library is uploaded again since its content may have changed.
+func customizeDiffLibraryContentHash(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown("source") {
+		return d.SetNewComputed("content_hash")
+	}
+	previousHash := d.Get("content_hash").(string)
+	contentHash, err := fileSha256(d.Get("source").(string))
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := d.SetNewComputed("content_hash"); err != nil {
+			return err
+		}
+		if d.Id() == "" || previousHash == "" {
+			return nil
+		}
+		return d.ForceNew("content_hash")
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read the library source: %s", err)
+	}
+	if previousHash == contentHash {
+		return nil
+	}
+	if err := d.SetNew("content_hash", contentHash); err != nil {
+		return err
+	}
+	// Imported libraries have no known content yet, their source is adopted as is
+	if d.Id() == "" || previousHash == "" {
+		return nil
+	}
+	return d.ForceNew("content_hash")
+}
+
+// resourceGraalSystemsLibraryCreate uploads a library
+func resourceGraalSystemsLibraryCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	name := d.Get("name").(string)
+	description := d.Get("description").(string)
+	source := d.Get("source").(string)
+
+	contentHash, err := fileSha256(source)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("cannot read the library source: %s", err))
+	}
+	file, err := os.Open(source)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("cannot read the library source: %s", err))
+	}
+	defer file.Close()
+
+	result, _, err := apiClient.LibraryAPI.CreateLibrary(context.Background()).XTenant(meta.tenant).Name(name).Description(description).File(file).Execute()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(*result.Id)
+	_ = d.Set("content_hash", contentHash)
+
+	return resourceGraalSystemsLibraryRead(ctx, d, meta)
+}
+
+// resourceGraalSystemsLibraryRead reads a library
+func resourceGraalSystemsLibraryRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	res, _, err := apiClient.LibraryAPI.FindLibraryById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil {
+		if is404Error(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	_ = d.Set("name", res.Name)
+	_ = d.Set("description", res.Description)
+	_ = d.Set("key", res.Key)
+
+	return nil
+}
+
+// resourceGraalSystemsLibraryUpdate updates the metadata of a library, a change of its content uploads a new one
+func resourceGraalSystemsLibraryUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	if patches := patchesFromResourceData(d, "name", "description"); len(patches) > 0 {
+		_, _, err := apiClient.LibraryAPI.UpdateLibrary(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceGraalSystemsLibraryRead(ctx, d, meta)
+}
+
+// resourceGraalSystemsLibraryDelete deletes a library
+func resourceGraalSystemsLibraryDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	_, err := apiClient.LibraryAPI.DeleteLibraryById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil && !is404Error(err) {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package graalsystems
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSha256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "library.whl")
+	assert.Nil(t, os.WriteFile(path, []byte("hello"), 0600))
+
+	hash, err := fileSha256(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hash)
+
+	_, err = fileSha256(filepath.Join(t.TempDir(), "missing.whl"))
+	assert.NotNil(t, err)
+}
+
+// libraryDiff plans the library against the state, running its CustomizeDiff
+func libraryDiff(t *testing.T, state *terraform.InstanceState, source string) *terraform.InstanceDiff {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"name": "library", "source": source})
+	diff, err := resourceGraalSystemsLibrary().Diff(context.Background(), state, config, nil)
+	assert.Nil(t, err)
+	return diff
+}
+
+func TestCustomizeDiffLibraryContentHash(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "library.whl")
+	assert.Nil(t, os.WriteFile(source, []byte("hello"), 0600))
+	hash, _ := fileSha256(source)
+	state := &terraform.InstanceState{ID: "library", Attributes: map[string]string{
+		"id": "library", "name": "library", "source": source, "content_hash": hash, "key": "key",
+	}}
+
+	// An unchanged file is not uploaded again
+	diff := libraryDiff(t, state, source)
+	assert.True(t, diff == nil || diff.Attributes["content_hash"] == nil)
+
+	// A changed content replaces the library
+	assert.Nil(t, os.WriteFile(source, []byte("world"), 0600))
+	diff = libraryDiff(t, state, source)
+	if assert.NotNil(t, diff) && assert.NotNil(t, diff.Attributes["content_hash"]) {
+		assert.True(t, diff.RequiresNew())
+		assert.Equal(t, hash, diff.Attributes["content_hash"].Old)
+		assert.NotEqual(t, hash, diff.Attributes["content_hash"].New)
+	}
+
+	// A file produced during the apply has an unknown hash, and replaces an existing library
+	missing := filepath.Join(t.TempDir(), "build", "library.whl")
+	diff = libraryDiff(t, state, missing)
+	if assert.NotNil(t, diff) && assert.NotNil(t, diff.Attributes["content_hash"]) {
+		assert.True(t, diff.Attributes["content_hash"].NewComputed)
+		assert.True(t, diff.RequiresNew())
+	}
+
+	// A new library is planned without failing
+	diff = libraryDiff(t, &terraform.InstanceState{}, missing)
+	if assert.NotNil(t, diff) && assert.NotNil(t, diff.Attributes["content_hash"]) {
+		assert.True(t, diff.Attributes["content_hash"].NewComputed)
+	}
+}
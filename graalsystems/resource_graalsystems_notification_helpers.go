@@ -0,0 +1,193 @@
+package graalsystems
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"sort"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	notificationTypeEmail   = "email"
+	notificationTypeWebhook = "webhook"
+	notificationTypeSlack   = "slack"
+)
+
+const (
+	notificationEventStart   = "start"
+	notificationEventSuccess = "success"
+	notificationEventFailure = "failure"
+	notificationEventTimeout = "timeout"
+)
+
+var notificationTypes = []string{notificationTypeEmail, notificationTypeWebhook, notificationTypeSlack}
+var notificationEvents = []string{notificationEventStart, notificationEventSuccess, notificationEventFailure, notificationEventTimeout}
+
+// notificationFields lists the attributes of the notification block used by each notification type
+var notificationFields = map[string][]string{
+	notificationTypeEmail:   {"recipients"},
+	notificationTypeWebhook: {"url"},
+	notificationTypeSlack:   {"url", "channel"},
+}
+
+// notificationRequiredFields lists the attributes of the notification block required by each notification type
+var notificationRequiredFields = map[string][]string{
+	notificationTypeEmail:   {"recipients"},
+	notificationTypeWebhook: {"url"},
+	notificationTypeSlack:   {"url"},
+}
+
+// notificationSchema defines the notification blocks shared by the jobs and the workflows
+func notificationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "List of notifications to send on the run events",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: fmt.Sprintf("Channel of the notification, one of %q", notificationTypes),
+					ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+						if !slices.Contains(notificationTypes, val.(string)) {
+							errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, notificationTypes, val))
+						}
+						return
+					},
+				},
+				"events": {
+					Type:        schema.TypeSet,
+					Required:    true,
+					MinItems:    1,
+					Description: fmt.Sprintf("Run events triggering the notification, among %q", notificationEvents),
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+						ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+							if !slices.Contains(notificationEvents, val.(string)) {
+								errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, notificationEvents, val))
+							}
+							return
+						},
+					},
+				},
+				"recipients": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Description: "Email addresses to notify. Only used if type is `email`",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+						ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+							if _, err := mail.ParseAddress(val.(string)); err != nil {
+								errs = append(errs, fmt.Errorf("%q must be a valid email address, got: %s", key, val))
+							}
+							return
+						},
+					},
+				},
+				"url": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "URL receiving the notification. Only used if type is `webhook` or `slack`",
+					ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+						if u, err := url.Parse(val.(string)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+							errs = append(errs, fmt.Errorf("%q must be a valid http or https URL", key))
+						}
+						return
+					},
+				},
+				"channel": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Channel to post the notification to, overriding the default channel of the webhook. Only used if type is `slack`",
+				},
+			},
+		},
+	}
+}
+
+// validateNotifications checks that every notification block only sets the attributes of its type
+func validateNotifications(input []interface{}) diag.Diagnostics {
+	for i, notification := range input {
+		n := notification.(map[string]interface{})
+		notificationType := n["type"].(string)
+		values := map[string]bool{
+			"recipients": len(n["recipients"].([]interface{})) > 0,
+			"url":        n["url"].(string) != "",
+			"channel":    n["channel"].(string) != "",
+		}
+		fields := notificationFields[notificationType]
+		for _, field := range notificationRequiredFields[notificationType] {
+			if !values[field] {
+				return diag.FromErr(fmt.Errorf("%s is required for notification type %s (notification %d)", field, notificationType, i))
+			}
+		}
+		for field, isSet := range values {
+			if isSet && !slices.Contains(fields, field) {
+				return diag.FromErr(fmt.Errorf("%s is not allowed for notification type %s (notification %d)", field, notificationType, i))
+			}
+		}
+	}
+	return nil
+}
+
+// defineNotifications converts the notification blocks to the notifications of the API.
+// Events are sorted, so that their order never produces a patch.
+func defineNotifications(input []interface{}) []sdk.Notification {
+	var notifications []sdk.Notification
+	for _, notification := range input {
+		n := notification.(map[string]interface{})
+		notificationType := n["type"].(string)
+		events := toStringList(n["events"].(*schema.Set).List())
+		sort.Strings(events)
+		notifications = append(notifications, sdk.Notification{
+			Type:       &notificationType,
+			Events:     events,
+			Recipients: toStringList(n["recipients"].([]interface{})),
+			Url:        expandStringPtr(n["url"]),
+			Channel:    expandStringPtr(n["channel"]),
+		})
+	}
+	return notifications
+}
+
+// patchNotifications creates the patches of the notifications when they changed
+func patchNotifications(d *schema.ResourceData) ([]sdk.Patch, diag.Diagnostics) {
+	if !d.HasChange("notification") {
+		return nil, nil
+	}
+	if diagnostics := validateNotifications(d.Get("notification").([]interface{})); diagnostics != nil {
+		return nil, diagnostics
+	}
+	patches, err := patchFromConvertedResourceData(d, "notification", "/notifications", func(v interface{}) interface{} {
+		return defineNotifications(v.([]interface{}))
+	})
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return patches, nil
+}
+
+// readNotifications converts the notifications returned by the API to the notification blocks
+func readNotifications(notifications *[]sdk.Notification) []map[string]interface{} {
+	if notifications == nil {
+		return nil
+	}
+	var result []map[string]interface{}
+	for _, notification := range *notifications {
+		result = append(result, map[string]interface{}{
+			"type":       flattenStringPtr(notification.Type),
+			"events":     flattenSliceString(notification.Events),
+			"recipients": flattenSliceString(notification.Recipients),
+			"url":        flattenStringPtr(notification.Url),
+			"channel":    flattenStringPtr(notification.Channel),
+		})
+	}
+	return result
+}
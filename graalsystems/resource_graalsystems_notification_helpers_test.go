@@ -0,0 +1,54 @@
+package graalsystems
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func notificationBlock(notificationType string, events []interface{}, recipients []interface{}, url string, channel string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       notificationType,
+		"events":     schema.NewSet(schema.HashString, events),
+		"recipients": recipients,
+		"url":        url,
+		"channel":    channel,
+	}
+}
+
+func TestValidateNotifications(t *testing.T) {
+	events := []interface{}{notificationEventFailure}
+
+	assert.Nil(t, validateNotifications([]interface{}{
+		notificationBlock(notificationTypeEmail, events, []interface{}{"ops@acme.com"}, "", ""),
+		notificationBlock(notificationTypeWebhook, events, []interface{}{}, "https://acme.com/hook", ""),
+		notificationBlock(notificationTypeSlack, events, []interface{}{}, "https://hooks.slack.com/services/x", "#alerts"),
+	}))
+
+	// Missing required fields
+	assert.NotNil(t, validateNotifications([]interface{}{notificationBlock(notificationTypeEmail, events, []interface{}{}, "", "")}))
+	assert.NotNil(t, validateNotifications([]interface{}{notificationBlock(notificationTypeSlack, events, []interface{}{}, "", "#alerts")}))
+	// Fields of another type
+	assert.NotNil(t, validateNotifications([]interface{}{notificationBlock(notificationTypeEmail, events, []interface{}{"ops@acme.com"}, "https://acme.com/hook", "")}))
+	assert.NotNil(t, validateNotifications([]interface{}{notificationBlock(notificationTypeWebhook, events, []interface{}{}, "https://acme.com/hook", "#alerts")}))
+}
+
+func TestDefineAndReadNotifications(t *testing.T) {
+	notifications := defineNotifications([]interface{}{
+		notificationBlock(notificationTypeSlack, []interface{}{notificationEventTimeout, notificationEventFailure}, []interface{}{}, "https://hooks.slack.com/services/x", "#alerts"),
+	})
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, []string{notificationEventFailure, notificationEventTimeout}, notifications[0].Events)
+	assert.Nil(t, notifications[0].Recipients)
+
+	assert.Equal(t, []map[string]interface{}{{
+		"type":       notificationTypeSlack,
+		"events":     []interface{}{notificationEventFailure, notificationEventTimeout},
+		"recipients": []interface{}{},
+		"url":        "https://hooks.slack.com/services/x",
+		"channel":    "#alerts",
+	}}, readNotifications(&notifications))
+
+	assert.Nil(t, readNotifications(nil))
+}
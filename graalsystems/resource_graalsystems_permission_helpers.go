@@ -0,0 +1,252 @@
+package graalsystems
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	permissionPrincipalUser     = "user"
+	permissionPrincipalGroup    = "group"
+	permissionPrincipalIdentity = "identity"
+)
+
+const (
+	permissionRoleViewer = "viewer"
+	permissionRoleEditor = "editor"
+	permissionRoleAdmin  = "admin"
+)
+
+const (
+	permissionTargetProject   = "project"
+	permissionTargetWorkflow  = "workflow"
+	permissionTargetWorkspace = "workspace"
+)
+
+var permissionPrincipalTypes = []string{permissionPrincipalUser, permissionPrincipalGroup, permissionPrincipalIdentity}
+var permissionRoles = []string{permissionRoleViewer, permissionRoleEditor, permissionRoleAdmin}
+
+// permissionBinding binds a principal to a role on an object
+type permissionBinding struct {
+	PrincipalType string
+	PrincipalId   string
+	Role          string
+}
+
+// permissionTarget gives access to the permissions of a type of object
+type permissionTarget struct {
+	find   func(meta *Meta, id string) ([]sdk.Permission, error)
+	add    func(meta *Meta, id string, permission sdk.Permission) error
+	remove func(meta *Meta, id string, permissionId string) error
+}
+
+// permissionTargets lists the types of objects supporting permissions
+var permissionTargets = map[string]permissionTarget{
+	permissionTargetProject: {
+		find: func(meta *Meta, id string) ([]sdk.Permission, error) {
+			permissions, _, err := meta.apiClient.ProjectAPI.FindPermissionsByProjectId(context.Background(), id).XTenant(meta.tenant).Execute()
+			return permissions, err
+		},
+		add: func(meta *Meta, id string, permission sdk.Permission) error {
+			_, _, err := meta.apiClient.ProjectAPI.AddPermissionToProject(context.Background(), id).XTenant(meta.tenant).Permission(permission).Execute()
+			return err
+		},
+		remove: func(meta *Meta, id string, permissionId string) error {
+			_, err := meta.apiClient.ProjectAPI.RemovePermissionFromProject(context.Background(), id, permissionId).XTenant(meta.tenant).Execute()
+			return err
+		},
+	},
+	permissionTargetWorkflow: {
+		find: func(meta *Meta, id string) ([]sdk.Permission, error) {
+			permissions, _, err := meta.apiClient.WorkflowAPI.FindPermissionsByWorkflowId(context.Background(), id).XTenant(meta.tenant).Execute()
+			return permissions, err
+		},
+		add: func(meta *Meta, id string, permission sdk.Permission) error {
+			_, _, err := meta.apiClient.WorkflowAPI.AddPermissionToWorkflow(context.Background(), id).XTenant(meta.tenant).Permission(permission).Execute()
+			return err
+		},
+		remove: func(meta *Meta, id string, permissionId string) error {
+			_, err := meta.apiClient.WorkflowAPI.RemovePermissionFromWorkflow(context.Background(), id, permissionId).XTenant(meta.tenant).Execute()
+			return err
+		},
+	},
+	permissionTargetWorkspace: {
+		find: func(meta *Meta, id string) ([]sdk.Permission, error) {
+			permissions, _, err := meta.apiClient.WorkspaceAPI.FindPermissionsByWorkspaceId(context.Background(), id).XTenant(meta.tenant).Execute()
+			return permissions, err
+		},
+		add: func(meta *Meta, id string, permission sdk.Permission) error {
+			_, _, err := meta.apiClient.WorkspaceAPI.AddPermissionToWorkspace(context.Background(), id).XTenant(meta.tenant).Permission(permission).Execute()
+			return err
+		},
+		remove: func(meta *Meta, id string, permissionId string) error {
+			_, err := meta.apiClient.WorkspaceAPI.RemovePermissionFromWorkspace(context.Background(), id, permissionId).XTenant(meta.tenant).Execute()
+			return err
+		},
+	},
+}
+
+// permissionSchema defines the permission blocks shared by the project permissions and the ACLs
+func permissionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "The principals bound to a role",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"principal_type": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: fmt.Sprintf("The type of the principal, one of %q", permissionPrincipalTypes),
+					ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+						if !slices.Contains(permissionPrincipalTypes, val.(string)) {
+							errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, permissionPrincipalTypes, val))
+						}
+						return
+					},
+				},
+				"principal_id": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The id of the user, the group or the identity",
+				},
+				"role": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: fmt.Sprintf("The role granted to the principal, one of %q", permissionRoles),
+					ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+						if !slices.Contains(permissionRoles, val.(string)) {
+							errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, permissionRoles, val))
+						}
+						return
+					},
+				},
+			},
+		},
+	}
+}
+
+// expandPermissionBindings converts the permission blocks to bindings
+func expandPermissionBindings(input interface{}) []permissionBinding {
+	var bindings []permissionBinding
+	for _, permission := range input.(*schema.Set).List() {
+		p := permission.(map[string]interface{})
+		bindings = append(bindings, permissionBinding{
+			PrincipalType: p["principal_type"].(string),
+			PrincipalId:   p["principal_id"].(string),
+			Role:          p["role"].(string),
+		})
+	}
+	return bindings
+}
+
+// flattenPermissionBindings converts the bindings to permission blocks
+func flattenPermissionBindings(bindings []permissionBinding) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, binding := range bindings {
+		result = append(result, map[string]interface{}{
+			"principal_type": binding.PrincipalType,
+			"principal_id":   binding.PrincipalId,
+			"role":           binding.Role,
+		})
+	}
+	return result
+}
+
+// permissionBindingIds maps the bindings of the permissions returned by the API to the ids of the permissions
+func permissionBindingIds(permissions []sdk.Permission) map[permissionBinding]string {
+	ids := make(map[permissionBinding]string)
+	for _, permission := range permissions {
+		binding := permissionBinding{
+			PrincipalType: flattenStringPtr(permission.PrincipalType),
+			PrincipalId:   flattenStringPtr(permission.PrincipalId),
+			Role:          flattenStringPtr(permission.Role),
+		}
+		ids[binding] = flattenStringPtr(permission.Id)
+	}
+	return ids
+}
+
+// currentPermissionBindings returns the bindings of an object, restricted to the managed ones unless the permissions are authoritative
+func currentPermissionBindings(ids map[permissionBinding]string, managed []permissionBinding, authoritative bool) []permissionBinding {
+	var bindings []permissionBinding
+	for binding := range ids {
+		bindings = append(bindings, binding)
+	}
+	slices.SortFunc(bindings, func(a, b permissionBinding) int {
+		return strings.Compare(a.PrincipalType+"/"+a.PrincipalId+"/"+a.Role, b.PrincipalType+"/"+b.PrincipalId+"/"+b.Role)
+	})
+	return managedElements(bindings, managed, authoritative)
+}
+
+// reconcilePermissions adds the wanted bindings missing from the object, and removes the managed bindings not wanted anymore.
+// An authoritative reconciliation removes every binding that is not wanted.
+func reconcilePermissions(meta *Meta, targetType string, id string, wanted []permissionBinding, managed []permissionBinding, authoritative bool) error {
+	target := permissionTargets[targetType]
+
+	permissions, err := target.find(meta, id)
+	if err != nil {
+		return err
+	}
+	ids := permissionBindingIds(permissions)
+	toAdd, toRemove := reconcileChanges(currentPermissionBindings(ids, nil, true), managed, wanted, authoritative)
+
+	for _, binding := range toAdd {
+		permission := sdk.Permission{PrincipalType: &binding.PrincipalType, PrincipalId: &binding.PrincipalId, Role: &binding.Role}
+		if err := target.add(meta, id, permission); err != nil {
+			return fmt.Errorf("cannot bind %s %s to the role %s of %s %s: %s", binding.PrincipalType, binding.PrincipalId, binding.Role, targetType, id, err)
+		}
+	}
+	for _, binding := range toRemove {
+		if err := target.remove(meta, id, ids[binding]); err != nil && !is404Error(err) {
+			return fmt.Errorf("cannot unbind %s %s from the role %s of %s %s: %s", binding.PrincipalType, binding.PrincipalId, binding.Role, targetType, id, err)
+		}
+	}
+	return nil
+}
+
+// createPermissions binds the principals of the permission blocks to the object
+func createPermissions(d *schema.ResourceData, meta *Meta, targetType string, id string) error {
+	wanted := expandPermissionBindings(d.Get("permission"))
+	return reconcilePermissions(meta, targetType, id, wanted, nil, d.Get("authoritative").(bool))
+}
+
+// updatePermissions applies the changes of the permission blocks to the object
+func updatePermissions(d *schema.ResourceData, meta *Meta, targetType string, id string) error {
+	if !d.HasChange("permission") {
+		return nil
+	}
+	old, val := d.GetChange("permission")
+	return reconcilePermissions(meta, targetType, id, expandPermissionBindings(val), expandPermissionBindings(old), d.Get("authoritative").(bool))
+}
+
+// deletePermissions removes the bindings of the permission blocks from the object
+func deletePermissions(d *schema.ResourceData, meta *Meta, targetType string, id string) error {
+	err := reconcilePermissions(meta, targetType, id, nil, expandPermissionBindings(d.Get("permission")), false)
+	if err != nil && is404Error(err) {
+		return nil
+	}
+	return err
+}
+
+// readPermissions sets the permission blocks from the bindings of the object.
+// A non-authoritative resource ignores the bindings managed elsewhere.
+// It returns false if the object does not exist anymore.
+func readPermissions(d *schema.ResourceData, meta *Meta, targetType string, id string) (bool, error) {
+	permissions, err := permissionTargets[targetType].find(meta, id)
+	if err != nil {
+		if is404Error(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	managed := expandPermissionBindings(d.Get("permission"))
+	bindings := currentPermissionBindings(permissionBindingIds(permissions), managed, d.Get("authoritative").(bool))
+	_ = d.Set("permission", flattenPermissionBindings(bindings))
+	return true, nil
+}
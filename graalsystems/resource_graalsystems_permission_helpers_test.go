@@ -0,0 +1,62 @@
+package graalsystems
+
+import (
+	"testing"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcilePermissionChanges(t *testing.T) {
+	aliceViewer := permissionBinding{PrincipalType: permissionPrincipalUser, PrincipalId: "alice", Role: permissionRoleViewer}
+	aliceEditor := permissionBinding{PrincipalType: permissionPrincipalUser, PrincipalId: "alice", Role: permissionRoleEditor}
+	dataAdmin := permissionBinding{PrincipalType: permissionPrincipalGroup, PrincipalId: "data", Role: permissionRoleAdmin}
+	ciEditor := permissionBinding{PrincipalType: permissionPrincipalIdentity, PrincipalId: "ci", Role: permissionRoleEditor}
+
+	// Non-authoritative: the bindings managed elsewhere are kept
+	current := []permissionBinding{aliceViewer, dataAdmin}
+	toAdd, toRemove := reconcileChanges(current, []permissionBinding{aliceViewer}, []permissionBinding{aliceEditor}, false)
+	assert.Equal(t, []permissionBinding{aliceEditor}, toAdd)
+	assert.Equal(t, []permissionBinding{aliceViewer}, toRemove)
+
+	// Authoritative: every current binding is managed
+	toAdd, toRemove = reconcileChanges(current, nil, []permissionBinding{dataAdmin, ciEditor}, true)
+	assert.Equal(t, []permissionBinding{ciEditor}, toAdd)
+	assert.Equal(t, []permissionBinding{aliceViewer}, toRemove)
+
+	// Managed bindings already removed outside of Terraform are not removed again
+	toAdd, toRemove = reconcileChanges(nil, []permissionBinding{aliceViewer}, nil, false)
+	assert.Nil(t, toAdd)
+	assert.Nil(t, toRemove)
+}
+
+func TestCurrentPermissionBindings(t *testing.T) {
+	permission := func(id string, principalType string, principalId string, role string) sdk.Permission {
+		return sdk.Permission{Id: &id, PrincipalType: &principalType, PrincipalId: &principalId, Role: &role}
+	}
+	ids := permissionBindingIds([]sdk.Permission{
+		permission("1", permissionPrincipalUser, "bob", permissionRoleViewer),
+		permission("2", permissionPrincipalGroup, "data", permissionRoleAdmin),
+		permission("3", permissionPrincipalUser, "alice", permissionRoleEditor),
+	})
+	alice := permissionBinding{PrincipalType: permissionPrincipalUser, PrincipalId: "alice", Role: permissionRoleEditor}
+	bob := permissionBinding{PrincipalType: permissionPrincipalUser, PrincipalId: "bob", Role: permissionRoleViewer}
+	data := permissionBinding{PrincipalType: permissionPrincipalGroup, PrincipalId: "data", Role: permissionRoleAdmin}
+
+	assert.Equal(t, "3", ids[alice])
+	assert.Equal(t, []permissionBinding{data, alice, bob}, currentPermissionBindings(ids, nil, true))
+	assert.Equal(t, []permissionBinding{bob}, currentPermissionBindings(ids, []permissionBinding{bob}, false))
+	assert.Nil(t, currentPermissionBindings(ids, nil, false))
+}
+
+func TestParseAclId(t *testing.T) {
+	resourceType, resourceId, err := parseAclId("workflow/xxxx")
+	assert.NoError(t, err)
+	assert.Equal(t, permissionTargetWorkflow, resourceType)
+	assert.Equal(t, "xxxx", resourceId)
+
+	for _, id := range []string{"xxxx", "workflow/", "project/xxxx"} {
+		_, _, err = parseAclId(id)
+		assert.Error(t, err, id)
+	}
+}
@@ -75,18 +75,8 @@ func resourceGraalSystemsProjectUpdate(ctx context.Context, d *schema.ResourceDa
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
-	if d.HasChange("name") {
-		path := "/name"
-
-		value := d.Get("name").(string)
-
-		patch := &sdk.Patch{
-			Op:    nil,
-			Path:  &path,
-			Value: &value,
-		}
-		patchs := &[]sdk.Patch{*patch}
-		_, _, err := apiClient.ProjectAPI.UpdateProject(context.Background(), d.Id()).XTenant(meta.tenant).Patch(*patchs).Execute()
+	if patches := patchesFromResourceData(d, "name", "description"); len(patches) > 0 {
+		_, _, err := apiClient.ProjectAPI.UpdateProject(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -0,0 +1,95 @@
+package graalsystems
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceGraalSystemsProjectPermission defines the schema for the project permission resource
+// An authoritative resource owns every binding of the project, and removes the ones it does not list.
+// A non-authoritative resource only adds and removes the bindings it lists.
+func resourceGraalSystemsProjectPermission() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsProjectPermissionCreate,
+		ReadContext:   resourceGraalSystemsProjectPermissionRead,
+		UpdateContext: resourceGraalSystemsProjectPermissionUpdate,
+		DeleteContext: resourceGraalSystemsProjectPermissionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGraalSystemsProjectPermissionImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the project",
+			},
+			"permission": permissionSchema(),
+			"authoritative": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "Whether the resource owns every binding of the project, removing the bindings it does not list",
+			},
+		},
+	}
+}
+
+// resourceGraalSystemsProjectPermissionImport imports the bindings of a project as authoritative permissions
+func resourceGraalSystemsProjectPermissionImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	_ = d.Set("project_id", d.Id())
+	_ = d.Set("authoritative", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceGraalSystemsProjectPermissionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	projectId := d.Get("project_id").(string)
+	if err := createPermissions(d, meta, permissionTargetProject, projectId); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(projectId)
+
+	return resourceGraalSystemsProjectPermissionRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsProjectPermissionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	found, err := readPermissions(d, meta, permissionTargetProject, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !found {
+		d.SetId("")
+		return nil
+	}
+	_ = d.Set("project_id", d.Id())
+
+	return nil
+}
+
+func resourceGraalSystemsProjectPermissionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	if err := updatePermissions(d, meta, permissionTargetProject, d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceGraalSystemsProjectPermissionRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsProjectPermissionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+
+	if err := deletePermissions(d, meta, permissionTargetProject, d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package graalsystems
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceGraalSystemsSecret defines the schema for the secret resource
+// The value of a secret is write-only: it is never read back from the API and only its hash is kept in the state
+func resourceGraalSystemsSecret() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGraalSystemsSecretCreate,
+		ReadContext:   resourceGraalSystemsSecretRead,
+		UpdateContext: resourceGraalSystemsSecretUpdate,
+		DeleteContext: resourceGraalSystemsSecretDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the secret",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description of the secret",
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				StateFunc:   hashSecretValue,
+				Description: "The value of the secret. Only its SHA-256 hash is stored in the state",
+			},
+			"version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The version of the secret, incremented by the API each time the value changes",
+			},
+		},
+	}
+}
+
+// hashSecretValue returns the hex encoded SHA-256 hash of a secret value, so that the value itself never lands in the state
+func hashSecretValue(v interface{}) string {
+	hash := sha256.Sum256([]byte(v.(string)))
+	return hex.EncodeToString(hash[:])
+}
+
+func resourceGraalSystemsSecretCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	name := d.Get("name").(string)
+	description := d.Get("description").(string)
+	value := d.Get("value").(string)
+	secret := &sdk.Secret{
+		Name:        &name,
+		Description: &description,
+		Value:       &value,
+	}
+	result, _, err := apiClient.SecretAPI.CreateSecret(context.Background()).XTenant(meta.tenant).Secret(*secret).Execute()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(*result.Id)
+
+	return resourceGraalSystemsSecretRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsSecretRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	res, _, err := apiClient.SecretAPI.FindSecretById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil {
+		if is404Error(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	_ = d.Set("name", res.Name)
+	_ = d.Set("description", res.Description)
+	readSecretVersion(d, flattenInt32Ptr(res.Version))
+
+	return nil
+}
+
+// readSecretVersion sets the version of the secret read from the API.
+// The value is never returned by the API: a new version means it was changed outside of Terraform,
+// forgetting its hash makes the next plan write the configured value again.
+func readSecretVersion(d *schema.ResourceData, version int) {
+	if previousVersion, ok := d.GetOk("version"); ok && previousVersion.(int) != version {
+		_ = d.Set("value", "")
+	}
+	_ = d.Set("version", version)
+}
+
+func resourceGraalSystemsSecretUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	patches := patchesFromResourceData(d, "name", "description")
+	// The state only knows the hash of the value, so the value is always replaced as a whole
+	if d.HasChange("value") {
+		patches = append(patches, newPatch(patchOpReplace, "/value", d.Get("value").(string)))
+	}
+	if len(patches) > 0 {
+		result, _, err := apiClient.SecretAPI.UpdateSecret(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		// Our own change of the value is not a drift
+		_ = d.Set("version", flattenInt32Ptr(result.Version))
+	}
+
+	return resourceGraalSystemsSecretRead(ctx, d, meta)
+}
+
+func resourceGraalSystemsSecretDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*Meta)
+	apiClient := meta.apiClient
+
+	_, err := apiClient.SecretAPI.DeleteSecretById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	if err != nil && !is404Error(err) {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
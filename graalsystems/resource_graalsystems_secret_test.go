@@ -0,0 +1,69 @@
+package graalsystems
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashSecretValue(t *testing.T) {
+	assert.Equal(t, "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", hashSecretValue("secret"))
+	assert.NotEqual(t, hashSecretValue("secret"), hashSecretValue("Secret"))
+}
+
+// secretState returns the state of a secret whose value is stored as its hash
+func secretState(value string, version string) *terraform.InstanceState {
+	return &terraform.InstanceState{ID: "secret", Attributes: map[string]string{
+		"id": "secret", "name": "secret", "value": hashSecretValue(value), "version": version,
+	}}
+}
+
+func TestSecretValue_StateFunc(t *testing.T) {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"name": "secret", "value": "my-password"})
+
+	// Only the hash of the value is planned into the state
+	diff, err := resourceGraalSystemsSecret().Diff(context.Background(), &terraform.InstanceState{}, config, nil)
+	assert.Nil(t, err)
+	if assert.NotNil(t, diff) && assert.NotNil(t, diff.Attributes["value"]) {
+		assert.Equal(t, hashSecretValue("my-password"), diff.Attributes["value"].New)
+		assert.NotContains(t, diff.Attributes["value"].New, "my-password")
+	}
+
+	// The same value has no diff, another one has
+	diff, err = resourceGraalSystemsSecret().Diff(context.Background(), secretState("my-password", "1"), config, nil)
+	assert.Nil(t, err)
+	assert.True(t, diff == nil || diff.Attributes["value"] == nil)
+	diff, err = resourceGraalSystemsSecret().Diff(context.Background(), secretState("old-password", "1"), config, nil)
+	assert.Nil(t, err)
+	if assert.NotNil(t, diff) {
+		assert.NotNil(t, diff.Attributes["value"])
+	}
+}
+
+func TestReadSecretVersion(t *testing.T) {
+	d := resourceGraalSystemsSecret().Data(secretState("my-password", "1"))
+
+	// The same version keeps the hash of the value
+	readSecretVersion(d, 1)
+	assert.Equal(t, hashSecretValue("my-password"), d.Get("value"))
+	assert.Equal(t, 1, d.Get("version"))
+
+	// A new version forgets the hash, so that the next plan writes the configured value again
+	readSecretVersion(d, 2)
+	assert.Equal(t, "", d.Get("value"))
+	assert.Equal(t, 2, d.Get("version"))
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"name": "secret", "value": "my-password"})
+	diff, err := resourceGraalSystemsSecret().Diff(context.Background(), d.State(), config, nil)
+	assert.Nil(t, err)
+	if assert.NotNil(t, diff) && assert.NotNil(t, diff.Attributes["value"]) {
+		assert.Equal(t, hashSecretValue("my-password"), diff.Attributes["value"].New)
+	}
+
+	// A secret without version yet, e.g. just imported, keeps its value
+	d = resourceGraalSystemsSecret().Data(&terraform.InstanceState{ID: "secret", Attributes: map[string]string{"id": "secret"}})
+	readSecretVersion(d, 3)
+	assert.Equal(t, 3, d.Get("version"))
+}
@@ -2,6 +2,9 @@ package graalsystems
 
 import (
 	"context"
+	"fmt"
+	"net/mail"
+	"sort"
 
 	sdk "github.com/graalsystems/sdk/go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -18,29 +21,88 @@ func resourceGraalSystemsUser() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Schema: map[string]*schema.Schema{
-			"name": {
+			"username": {
 				Type:        schema.TypeString,
 				Required:    true,
-				Description: "The name of the user",
+				Description: "The username of the user. Renaming a user keeps its account",
+			},
+			"email": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The email address of the user",
+				ValidateFunc: validateEmailAddress,
+			},
+			"first_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The first name of the user",
+			},
+			"last_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The last name of the user",
 			},
 			"description": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "The description of the user",
 			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the user can log in. Disabling a user keeps its configuration, e.g. when offboarding someone",
+			},
+			"group_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Description: "The ids of the groups the user is member of. Left unset, the groups are not managed by this resource",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"send_invitation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether an invitation email is sent to the user once created. Only used at creation",
+			},
 		},
 	}
 }
 
+// validateEmailAddress checks that the value is a bare email address, e.g. `jane@acme.com`
+func validateEmailAddress(val any, key string) (warns []string, errs []error) {
+	if address, err := mail.ParseAddress(val.(string)); err != nil || address.Address != val.(string) {
+		errs = append(errs, fmt.Errorf("%q must be a valid email address, got: %s", key, val))
+	}
+	return
+}
+
+// expandUserGroups converts the group ids set to a sorted list, so that their order never produces a patch
+func expandUserGroups(groupIds interface{}) []string {
+	groups := toStringList(groupIds.(*schema.Set).List())
+	sort.Strings(groups)
+	return groups
+}
+
 func resourceGraalSystemsUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
 	username := d.Get("username").(string)
+	email := d.Get("email").(string)
 	description := d.Get("description").(string)
+	enabled := d.Get("enabled").(bool)
 	user := &sdk.User{
 		Username:    &username,
+		Email:       &email,
+		FirstName:   expandStringPtr(d.Get("first_name")),
+		LastName:    expandStringPtr(d.Get("last_name")),
 		Description: &description,
+		Enabled:     &enabled,
+	}
+	if groupIds, ok := d.GetOk("group_ids"); ok {
+		user.Groups = expandUserGroups(groupIds)
 	}
 	result, _, err := apiClient.UserAPI.CreateUser(context.Background()).XTenant(meta.tenant).User(*user).Execute()
 	if err != nil {
@@ -49,6 +111,12 @@ func resourceGraalSystemsUserCreate(ctx context.Context, d *schema.ResourceData,
 
 	d.SetId(*result.Id)
 
+	if d.Get("send_invitation").(bool) {
+		if _, err := apiClient.UserAPI.InviteUser(context.Background(), d.Id()).XTenant(meta.tenant).Execute(); err != nil {
+			return diag.FromErr(fmt.Errorf("user %s created, but the invitation could not be sent: %s", username, err))
+		}
+	}
+
 	return resourceGraalSystemsUserRead(ctx, d, meta)
 }
 
@@ -66,7 +134,12 @@ func resourceGraalSystemsUserRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	_ = d.Set("username", res.Username)
-	_ = d.Set("description", res.Description)
+	_ = d.Set("email", res.Email)
+	_ = d.Set("first_name", flattenStringPtr(res.FirstName))
+	_ = d.Set("last_name", flattenStringPtr(res.LastName))
+	_ = d.Set("description", flattenStringPtr(res.Description))
+	_ = d.Set("enabled", res.Enabled == nil || *res.Enabled)
+	_ = d.Set("group_ids", res.Groups)
 
 	return nil
 }
@@ -75,18 +148,20 @@ func resourceGraalSystemsUserUpdate(ctx context.Context, d *schema.ResourceData,
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
-	if d.HasChange("username") {
-		path := "/username"
-
-		value := d.Get("username").(string)
-
-		patch := &sdk.Patch{
-			Op:    nil,
-			Path:  &path,
-			Value: &value,
+	patches := patchesFromResourceData(d, "username", "email", "first_name", "last_name", "description", "enabled")
+	// The groups are only patched when managed by this resource, so that the group memberships are not overridden
+	if !isNullInConfig(d, "group_ids") {
+		groupPatches, err := patchFromConvertedResourceData(d, "group_ids", "/groups", func(v interface{}) interface{} {
+			return expandUserGroups(v)
+		})
+		if err != nil {
+			return diag.FromErr(err)
 		}
-		patchs := &[]sdk.Patch{*patch}
-		_, _, err := apiClient.UserAPI.UpdateUser(context.Background(), d.Id()).XTenant(meta.tenant).Patch(*patchs).Execute()
+		patches = append(patches, groupPatches...)
+	}
+
+	if len(patches) > 0 {
+		_, _, err := apiClient.UserAPI.UpdateUser(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
 		if err != nil {
 			return diag.FromErr(err)
 		}
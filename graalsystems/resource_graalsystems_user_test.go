@@ -0,0 +1,63 @@
+package graalsystems
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEmailAddress(t *testing.T) {
+	for _, email := range []string{"jane@acme.com", "jane.doe+data@acme.co.uk"} {
+		_, errs := validateEmailAddress(email, "email")
+		assert.Empty(t, errs, email)
+	}
+	for _, email := range []string{"", "jane", "jane@", "Jane Doe <jane@acme.com>"} {
+		_, errs := validateEmailAddress(email, "email")
+		assert.Len(t, errs, 1, email)
+	}
+}
+
+func TestExpandUserGroups(t *testing.T) {
+	groups := schema.NewSet(schema.HashString, []interface{}{"ops", "data", "admins"})
+	assert.Equal(t, []string{"admins", "data", "ops"}, expandUserGroups(groups))
+	assert.Empty(t, expandUserGroups(schema.NewSet(schema.HashString, nil)))
+}
+
+func TestUserRename_InPlace(t *testing.T) {
+	userSchema := resourceGraalSystemsUser().Schema
+	state := &terraform.InstanceState{ID: "user", Attributes: map[string]string{
+		"id": "user", "username": "jane.doe", "email": "jane.doe@acme.com", "enabled": "true", "send_invitation": "false",
+	}}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"username": "jane.smith", "email": "jane.doe@acme.com"})
+
+	diff, err := schema.InternalMap(userSchema).Diff(context.Background(), state, config, nil, nil, true)
+	assert.Nil(t, err)
+	assert.False(t, diff.RequiresNew())
+
+	d, err := schema.InternalMap(userSchema).Data(state, diff)
+	assert.Nil(t, err)
+	patches := patchesFromResourceData(d, "username", "email")
+	assert.Equal(t, []string{"replace /username"}, patchSummary(patches))
+	assert.Equal(t, "jane.smith", patches[0].Value)
+}
+
+func TestUserGroupsNotManaged_NoDiff(t *testing.T) {
+	state := &terraform.InstanceState{ID: "user", Attributes: map[string]string{
+		"id": "user", "username": "jane.doe", "email": "jane.doe@acme.com", "enabled": "true", "send_invitation": "false",
+		"group_ids.#": "1", "group_ids.0": "data-team",
+	}}
+
+	// The groups added by a group membership are not removed when group_ids is unset
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"username": "jane.doe", "email": "jane.doe@acme.com"})
+	diff, err := resourceGraalSystemsUser().Diff(context.Background(), state, config, nil)
+	assert.Nil(t, err)
+	assert.True(t, diff == nil || diff.Empty())
+
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{"username": "jane.doe", "email": "jane.doe@acme.com", "group_ids": []interface{}{}})
+	diff, err = resourceGraalSystemsUser().Diff(context.Background(), state, config, nil)
+	assert.Nil(t, err)
+	assert.False(t, diff == nil || diff.Empty())
+}
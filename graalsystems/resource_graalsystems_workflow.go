@@ -21,6 +21,7 @@ func resourceGraalSystemsWorkflow() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: customizeDiffScheduleNextRuns,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -60,15 +61,16 @@ func resourceGraalSystemsWorkflow() *schema.Resource {
 							Required: true,
 						},
 						"cron_expression": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Cron expression of the schedule. Only used if type is `cron`",
-							//TODO: add validate for cron exp ?
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "Cron expression of the schedule. Only used if type is `cron`",
+							ValidateDiagFunc: validateCronExpression(),
 						},
 						"timezone": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Timezone of the schedule. Only used if type is `cron`",
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "IANA timezone of the schedule, e.g. `Europe/Paris`. Only used if type is `cron`",
+							ValidateDiagFunc: validateTimezone(),
 						},
 						"infrastructure_id": {
 							Type:        schema.TypeString,
@@ -119,8 +121,14 @@ func resourceGraalSystemsWorkflow() *schema.Resource {
 				Optional:    true,
 				Description: "Labels for every step of the job",
 			},
+			"next_runs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: fmt.Sprintf("The next %d fire times of the schedule, in RFC 3339 format, computed on every refresh. Empty if the schedule type is `once`", scheduleNextRunsCount),
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"notification": notificationSchema(),
 			/* TODO: add the following fields
-			"notifications"
 			"parameters"
 			"metadata"*/
 		},
@@ -160,14 +168,21 @@ func resourceGraalSystemsWorkflowCreate(ctx context.Context, d *schema.ResourceD
 		return diag.FromErr(errs)
 	}
 
+	notificationBlocks := d.Get("notification").([]interface{})
+	if diagnostics := validateNotifications(notificationBlocks); diagnostics != nil {
+		return diagnostics
+	}
+	notifications := defineNotifications(notificationBlocks)
+
 	workflow := &sdk.Workflow{
-		Name:        &name,
-		Description: &description,
-		ProjectId:   &projectId,
-		IdentityId:  &identityId,
-		Schedule:    &schedule,
-		Tasks:       defineTasks(jobs, "job"),
-		Labels:      &labels,
+		Name:          &name,
+		Description:   &description,
+		ProjectId:     &projectId,
+		IdentityId:    &identityId,
+		Schedule:      &schedule,
+		Tasks:         defineTasks(jobs, "job"),
+		Labels:        &labels,
+		Notifications: &notifications,
 	}
 
 	if registeredWorkflow, _, err := apiClient.ProjectAPI.CreateWorkflowForProject(context.Background(), projectId).XTenant(meta.tenant).Workflow(*workflow).Execute(); err != nil {
@@ -201,19 +216,18 @@ func resourceGraalSystemsWorkflowRead(ctx context.Context, d *schema.ResourceDat
 	_ = d.Set("project_id", workflow.ProjectId)
 	_ = d.Set("identity_id", workflow.IdentityId)
 
-	if schedule, errors := readSchedule(workflow.Schedule); err != nil {
-		return diag.FromErr(errors)
+	if schedule, er := readSchedule(workflow.Schedule); er != nil {
+		return diag.FromErr(er)
 	} else {
 		_ = d.Set("schedule", schedule)
+		_ = d.Set("next_runs", readNextRuns(schedule))
 	}
-	if tasks, er := readTasks(workflow.Tasks); err != nil {
+	if tasks, er := readTasks(workflow.Tasks); er != nil {
 		return diag.FromErr(er)
 	} else {
 		_ = d.Set("job", tasks)
 	}
-	if workflow.Notifications != nil {
-		_ = d.Set("notifications", *workflow.Notifications)
-	}
+	_ = d.Set("notification", readNotifications(workflow.Notifications))
 	_ = d.Set("labels", workflow.Labels)
 
 	return nil
@@ -225,48 +239,41 @@ func resourceGraalSystemsWorkflowUpdate(ctx context.Context, d *schema.ResourceD
 	apiClient := meta.apiClient
 
 	workflowId := d.Id()
-	if d.HasChange("name") {
-		_, _, err := apiClient.WorkflowAPI.UpdateWorkflow(context.Background(), workflowId).XTenant(meta.tenant).Patch(patchFromResourceData(d, "name")).Execute()
-		if err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	if d.HasChange("description") {
-		_, _, err := apiClient.WorkflowAPI.UpdateWorkflow(context.Background(), workflowId).XTenant(meta.tenant).Patch(patchFromResourceData(d, "description")).Execute()
-		if err != nil {
-			return diag.FromErr(err)
-		}
+	// All the changes are sent in a single request, so that a failure never leaves the workflow partially updated
+	patches := patchesFromResourceData(d, "name", "description", "labels")
+	notificationPatches, diagnostics := patchNotifications(d)
+	if diagnostics != nil {
+		return diagnostics
 	}
+	patches = append(patches, notificationPatches...)
 	if d.HasChange("schedule") {
-		return diag.FromErr(fmt.Errorf("cannot change schedule yet, please recreate the workflow"))
-		/*sch := d.Get("schedule").([]interface{})
-		if diagnostics := validateSchedule(sch[0]); diagnostics != nil {
+		schedulePatches, diagnostics := patchSchedule(d)
+		if diagnostics != nil {
 			return diagnostics
 		}
-		_, _, err := apiClient.WorkflowAPI.UpdateWorkflow(context.Background(), workflowId).XTenant(meta.tenant).Patch(patchFromResourceData(d, "schedule")).Execute()
+		patches = append(patches, schedulePatches...)
+	}
+	if d.HasChange("job") {
+		changedJobs, _ := d.GetOk("job")
+		if err := validateJobs(changedJobs.([]interface{})); err != nil {
+			return diag.FromErr(err)
+		}
+		// The patches are computed against the tasks stored on the server, which may differ from the state
+		workflow, _, err := apiClient.WorkflowAPI.FindWorkflowById(context.Background(), workflowId).XTenant(meta.tenant).Execute()
 		if err != nil {
 			return diag.FromErr(err)
-		}*/
-	}
-	if d.HasChange("labels") {
-		_, _, err := apiClient.WorkflowAPI.UpdateWorkflow(context.Background(), workflowId).XTenant(meta.tenant).Patch(patchFromResourceData(d, "labels")).Execute()
+		}
+		jobPatches, err := patchJobs(workflow.Tasks, changedJobs.([]interface{}))
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		patches = append(patches, jobPatches...)
 	}
-	if d.HasChange("job") {
-		changedJobs, _ := d.GetOk("job")
-		if err := validateJobs(changedJobs.([]interface{})); err != nil {
+	if len(patches) > 0 {
+		_, _, err := apiClient.WorkflowAPI.UpdateWorkflow(context.Background(), workflowId).XTenant(meta.tenant).Patch(patches).Execute()
+		if err != nil {
 			return diag.FromErr(err)
 		}
-		if jobPatches := patchJobs(); jobPatches == nil {
-			return diag.FromErr(fmt.Errorf("cannot yet update jobs, please recreate the workflow"))
-		} else {
-			_, _, err := apiClient.WorkflowAPI.UpdateWorkflow(context.Background(), workflowId).XTenant(meta.tenant).Patch(jobPatches).Execute()
-			if err != nil {
-				return diag.FromErr(err)
-			}
-		}
 	}
 
 	return resourceGraalSystemsWorkflowRead(ctx, d, m)
@@ -316,10 +323,10 @@ func readSchedule(sch sdk.ISchedule) ([]map[string]string, error) {
 		return []map[string]string{
 			{
 				"type":              *schedule.Type,
-				"cron_expression":   *cron.CronExpression,
-				"timezone":          *cron.Timezone,
-				"infrastructure_id": *cron.InfrastructureId,
-				"device_id":         *cron.DeviceId,
+				"cron_expression":   flattenStringPtr(cron.CronExpression),
+				"timezone":          flattenStringPtr(cron.Timezone),
+				"infrastructure_id": flattenStringPtr(cron.InfrastructureId),
+				"device_id":         flattenStringPtr(cron.DeviceId),
 			},
 		}, nil
 	}
@@ -346,7 +353,7 @@ func readTasks(tasks []sdk.ITask) ([]map[string]interface{}, error) {
 			taskRefs = append(taskRefs, map[string]interface{}{
 				"ref":        *jobTask.Ref,
 				"name":       *jobTask.Task.Name,
-				"depends_on": jobTask.Task.Depends,
+				"depends_on": flattenSliceString(jobTask.Task.Depends),
 			})
 		} else {
 			return nil, fmt.Errorf("task type %s is not yet supported", *sdkTask.Type)
@@ -359,7 +366,7 @@ func validateJobs(jobs []interface{}) error {
 	var jobNames []string
 	for i, job := range jobs {
 		j := job.(map[string]interface{})
-		if val, ok := j["ref"]; !ok {
+		if val, ok := j["ref"]; ok {
 			if _, err := uuid.ParseUUID(val.(string)); err != nil {
 				return err
 			}
@@ -400,7 +407,25 @@ func defineTasks(tasks []interface{}, taskType string) []sdk.ITask {
 	return jobTasks
 }
 
-func patchJobs() []sdk.Patch {
-	//TODO: implement patchJobs
-	return nil
+// patchJobs creates the patches transforming the tasks of the workflow into the configured jobs.
+// Tasks are compared by position, so added, removed, reordered and re-wired jobs are all patched in place.
+func patchJobs(tasks []sdk.ITask, jobs []interface{}) ([]sdk.Patch, error) {
+	// Only the attributes managed by terraform are compared, as the server may return additional ones
+	stored, err := readTasks(tasks)
+	if err != nil {
+		return nil, err
+	}
+	storedJobs := make([]interface{}, 0, len(stored))
+	for _, task := range stored {
+		storedJobs = append(storedJobs, task)
+	}
+	current, err := toJSONValue(defineTasks(storedJobs, "job"))
+	if err != nil {
+		return nil, err
+	}
+	wanted, err := toJSONValue(defineTasks(jobs, "job"))
+	if err != nil {
+		return nil, err
+	}
+	return diffPatches("/tasks", current, wanted), nil
 }
@@ -0,0 +1,93 @@
+package graalsystems
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testExtractJobId   = "2c1a1716-5570-4668-a50a-860c90beabf6"
+	testTransformJobId = "6a2f5b1e-3c0d-4b7a-9f1e-0d8c7b6a5e4f"
+	testLoadJobId      = "9b8c7d6e-5f4a-4b3c-8d2e-1f0a9b8c7d6e"
+)
+
+func jobBlock(ref string, name string, dependsOn ...string) map[string]interface{} {
+	return map[string]interface{}{"ref": ref, "name": name, "depends_on": flattenSliceString(dependsOn)}
+}
+
+func TestPatchJobs(t *testing.T) {
+	tasks := defineTasks([]interface{}{
+		jobBlock(testExtractJobId, "extract"),
+		jobBlock(testTransformJobId, "transform", "extract"),
+	}, "job")
+
+	patches, err := patchJobs(tasks, []interface{}{
+		jobBlock(testExtractJobId, "extract"),
+		jobBlock(testTransformJobId, "transform", "extract"),
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, patches)
+
+	// An added job is appended
+	patches, err = patchJobs(tasks, []interface{}{
+		jobBlock(testExtractJobId, "extract"),
+		jobBlock(testTransformJobId, "transform", "extract"),
+		jobBlock(testLoadJobId, "load", "transform"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"add /tasks/2"}, patchSummary(patches))
+	assert.Equal(t, map[string]interface{}{
+		"type": "job", "name": "load", "ref": testLoadJobId, "depends": []interface{}{"transform"},
+	}, patches[0].Value)
+
+	// A removed job is removed, the remaining ones being patched in place
+	patches, err = patchJobs(tasks, []interface{}{
+		jobBlock(testTransformJobId, "transform"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"replace /tasks/0/name", "replace /tasks/0/ref", "remove /tasks/1"}, patchSummary(patches))
+
+	// Reordered jobs are patched in place, including their dependencies
+	patches, err = patchJobs(tasks, []interface{}{
+		jobBlock(testTransformJobId, "transform"),
+		jobBlock(testExtractJobId, "extract", "transform"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"replace /tasks/0/name",
+		"replace /tasks/0/ref",
+		"replace /tasks/1/depends/0",
+		"replace /tasks/1/name",
+		"replace /tasks/1/ref",
+	}, patchSummary(patches))
+	assert.Equal(t, "transform", patches[2].Value)
+}
+
+func TestCustomizeDiffScheduleNextRuns(t *testing.T) {
+	state := &terraform.InstanceState{ID: "workflow", Attributes: map[string]string{
+		"id": "workflow", "name": "workflow", "project_id": "project", "identity_id": "identity",
+		"schedule.#": "1", "schedule.0.type": "cron", "schedule.0.cron_expression": "0 0 * * *", "schedule.0.timezone": "UTC",
+		"next_runs.#": "1", "next_runs.0": "2024-01-01T00:00:00Z",
+	}}
+	config := func(cronExpression string) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name": "workflow", "project_id": "project", "identity_id": "identity",
+			"schedule": []interface{}{map[string]interface{}{"type": "cron", "cron_expression": cronExpression, "timezone": "UTC"}},
+		})
+	}
+
+	// The next runs are not planned while the schedule is unchanged
+	diff, err := resourceGraalSystemsWorkflow().Diff(context.Background(), state, config("0 0 * * *"), nil)
+	assert.Nil(t, err)
+	assert.True(t, diff == nil || diff.Attributes["next_runs.#"] == nil)
+
+	// The next runs of a changed schedule are only known after the apply
+	diff, err = resourceGraalSystemsWorkflow().Diff(context.Background(), state, config("* * * * *"), nil)
+	assert.Nil(t, err)
+	if assert.NotNil(t, diff) && assert.NotNil(t, diff.Attributes["next_runs.#"]) {
+		assert.True(t, diff.Attributes["next_runs.#"].NewComputed)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	sdk "github.com/graalsystems/sdk/go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"slices"
+	"strings"
+	"time"
 )
 
 const (
@@ -19,6 +22,32 @@ const (
 
 var workspaceTypes = []string{workspaceTypeJupyter, workspaceTypeMetabase, workspaceTypeSuperset, workspaceTypeVsCode, workspaceTypeZeppelin}
 
+const (
+	workspaceStatusPending  = "pending"
+	workspaceStatusStarting = "starting"
+	workspaceStatusRunning  = "running"
+	workspaceStatusStopping = "stopping"
+	workspaceStatusStopped  = "stopped"
+	workspaceStatusDeleting = "deleting"
+	workspaceStatusFailed   = "failed"
+	// workspaceStatusDeleted is never returned by the API, it stands for a workspace that is not found anymore
+	workspaceStatusDeleted = "deleted"
+	// workspaceStatusUnchanged is never returned by the API, it stands for a workspace still in its status from before an action
+	workspaceStatusUnchanged = "unchanged"
+)
+
+// workspaceDesiredStates lists the stable statuses a workspace can be asked to reach
+var workspaceDesiredStates = []string{workspaceStatusRunning, workspaceStatusStopped}
+
+// workspaceTransitionalStatuses lists the statuses of a workspace that is moving from a stable status to another
+var workspaceTransitionalStatuses = []string{workspaceStatusPending, workspaceStatusStarting, workspaceStatusStopping, workspaceStatusDeleting}
+
+// workspacePollInterval is the delay between two status checks while waiting for a workspace
+const workspacePollInterval = 10 * time.Second
+
+// workspaceStatusChangeGracePeriod is how long a workspace can keep its status from before an action, until the API starts the action
+const workspaceStatusChangeGracePeriod = 1 * time.Minute
+
 // resourceGraalSystemsWorkspace defines the schema for the workspace resource
 // It also implements the CRUD methods
 func resourceGraalSystemsWorkspace() *schema.Resource {
@@ -30,6 +59,12 @@ func resourceGraalSystemsWorkspace() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: customizeDiffInstanceType("instance_type", "", "infrastructure_id"),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -44,6 +79,7 @@ func resourceGraalSystemsWorkspace() *schema.Resource {
 			"type": {
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 				Description: "The type of the workspace",
 				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
 					if !slices.Contains(workspaceTypes, val.(string)) {
@@ -55,27 +91,90 @@ func resourceGraalSystemsWorkspace() *schema.Resource {
 			"infrastructure_id": {
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 				Description: "The id of the infrastructure to deploy the workspace on",
 			},
 			"instance_type": {
 				Type:        schema.TypeString,
 				Required:    true,
-				Description: "The instance type of the compute used for the workspace",
+				Description: "The instance type of the compute used for the workspace. The `graalsystems_instance_types` data source lists the instance types available on the infrastructure",
 			},
 			"owner": {
 				Type:        schema.TypeString,
+				Optional:    true,
 				Computed:    true,
-				Description: "The owner of the workspace",
+				Description: "The id of the user owning the workspace. Defaults to the user the provider is authenticated with",
+			},
+			"env": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Environment variables of the workspace",
+			},
+			"volume": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Persistent volumes attached to the workspace",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the volume",
+						},
+						"mount_path": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The absolute path the volume is mounted on in the workspace",
+							ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+								if !strings.HasPrefix(val.(string), "/") {
+									errs = append(errs, fmt.Errorf("%q must be an absolute path, got: %s", key, val))
+								}
+								return
+							},
+						},
+						"size_gb": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							Description:  "The size of the volume, in GiB",
+							ValidateFunc: validatePositiveInt,
+						},
+					},
+				},
+			},
+			"idle_timeout_minutes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "The number of idle minutes after which the workspace is stopped. Never stopped if not set",
+				ValidateFunc: validatePositiveInt,
+			},
+			"desired_state": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: fmt.Sprintf("The state the workspace is started or stopped to, one of %q. If not set, a new workspace is started and then left in its current state", workspaceDesiredStates),
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if !slices.Contains(workspaceDesiredStates, val.(string)) {
+						errs = append(errs, fmt.Errorf("%q must be one of %q, got: %s", key, workspaceDesiredStates, val))
+					}
+					return
+				},
 			},
 			"status": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "The status of the workspace",
 			},
+			"status_message": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The message detailing the status of the workspace, e.g. the reason of a failure",
+			},
 			"version": {
 				Type:        schema.TypeString,
+				Optional:    true,
 				Computed:    true,
-				Description: "The version of the workspace type",
+				Description: "The version of the workspace type. Defaults to the latest version",
 			},
 			"public_url": {
 				Type:        schema.TypeString,
@@ -91,9 +190,14 @@ func resourceGraalSystemsWorkspaceCreate(ctx context.Context, d *schema.Resource
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
-	user, _, err := apiClient.UserAPI.FindCurrentUser(context.Background()).XTenant(meta.tenant).Execute()
-	if err != nil {
-		return diag.FromErr(err)
+	// Without explicit owner, the workspace belongs to the user the provider is authenticated with
+	owner := d.Get("owner").(string)
+	if owner == "" {
+		user, _, err := apiClient.UserAPI.FindCurrentUser(context.Background()).XTenant(meta.tenant).Execute()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		owner = *user.Id
 	}
 
 	name := d.Get("name").(string)
@@ -101,13 +205,18 @@ func resourceGraalSystemsWorkspaceCreate(ctx context.Context, d *schema.Resource
 	workspaceType := d.Get("type").(string)
 	infrastructureId := d.Get("infrastructure_id").(string)
 	instanceType := d.Get("instance_type").(string)
+	env := toStringMap(d.Get("env").(map[string]interface{}))
 	workspace := &sdk.Workspace{
-		Name:             &name,
-		Description:      &description,
-		Type:             &workspaceType,
-		InfrastructureId: &infrastructureId,
-		InstanceType:     &instanceType,
-		Owner:            user.Id,
+		Name:               &name,
+		Description:        &description,
+		Type:               &workspaceType,
+		InfrastructureId:   &infrastructureId,
+		InstanceType:       &instanceType,
+		Owner:              &owner,
+		Version:            expandStringPtr(d.Get("version")),
+		Env:                &env,
+		Volumes:            defineWorkspaceVolumes(d.Get("volume").([]interface{})),
+		IdleTimeoutMinutes: expandInt32Ptr(d.Get("idle_timeout_minutes")),
 	}
 	if result, request, err := apiClient.WorkspaceAPI.CreateWorkspace(context.Background()).XTenant(meta.tenant).Workspace(*workspace).Execute(); err != nil {
 		return diag.FromErr(err)
@@ -117,6 +226,16 @@ func resourceGraalSystemsWorkspaceCreate(ctx context.Context, d *schema.Resource
 		d.SetId(*result.Id)
 	}
 
+	// A new workspace is started, it is stopped afterward if needed, all within the create timeout
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutCreate))
+	if _, ok := d.GetOk("desired_state"); ok {
+		if diagnostics := applyWorkspaceDesiredState(ctx, meta, d, deadline); diagnostics != nil {
+			return diagnostics
+		}
+	} else if _, err := waitWorkspaceStatus(ctx, meta, d.Id(), "", workspaceDesiredStates, time.Until(deadline)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return resourceGraalSystemsWorkspaceRead(ctx, d, meta)
 }
 
@@ -141,10 +260,15 @@ func resourceGraalSystemsWorkspaceRead(_ context.Context, d *schema.ResourceData
 	_ = d.Set("type", res.Type)
 	_ = d.Set("infrastructure_id", res.InfrastructureId)
 	_ = d.Set("instance_type", res.InstanceType)
-	_ = d.Set("owner", *res.Owner)
-	_ = d.Set("version", *res.Version)
-	_ = d.Set("status", *res.Status)
-	_ = d.Set("public_url", *res.PublicUrl)
+	_ = d.Set("env", flattenStringMapPtr(res.Env))
+	_ = d.Set("volume", readWorkspaceVolumes(res.Volumes))
+	_ = d.Set("idle_timeout_minutes", flattenInt32Ptr(res.IdleTimeoutMinutes))
+	_ = d.Set("owner", flattenStringPtr(res.Owner))
+	_ = d.Set("version", flattenStringPtr(res.Version))
+	_ = d.Set("status", flattenStringPtr(res.Status))
+	_ = d.Set("status_message", flattenStringPtr(res.StatusMessage))
+	_ = d.Set("public_url", flattenStringPtr(res.PublicUrl))
+	_ = d.Set("desired_state", readWorkspaceDesiredState(d.Get("desired_state").(string), flattenStringPtr(res.Status), flattenInt32Ptr(res.IdleTimeoutMinutes)))
 
 	return nil
 }
@@ -154,37 +278,190 @@ func resourceGraalSystemsWorkspaceUpdate(ctx context.Context, d *schema.Resource
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
-	if d.HasChange("type") || d.HasChange("infrastructure_id") || d.HasChange("instance_type") {
-		return diag.FromErr(fmt.Errorf("once created, you cannot change the following workspace properties: " +
-			"['type', 'infrastructure_id', 'instance_type'] \nDelete the workspace and create a new one"))
+	status := d.Get("status").(string)
+	patches := patchesFromResourceData(d, "name", "description", "instance_type", "owner", "version", "env", "idle_timeout_minutes")
+	volumePatches, err := patchFromConvertedResourceData(d, "volume", "/volumes", func(v interface{}) interface{} {
+		return defineWorkspaceVolumes(v.([]interface{}))
+	})
+	if err != nil {
+		return diag.FromErr(err)
 	}
-
-	if d.HasChange("name") {
-		_, _, err := apiClient.WorkspaceAPI.UpdateWorkspace(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patchFromResourceData(d, "name")).Execute()
+	patches = append(patches, volumePatches...)
+	if len(patches) > 0 {
+		_, _, err := apiClient.WorkspaceAPI.UpdateWorkspace(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patches).Execute()
 		if err != nil {
 			return diag.FromErr(err)
 		}
 	}
-
-	if d.HasChange("description") {
-		_, _, err := apiClient.WorkspaceAPI.UpdateWorkspace(context.Background(), d.Id()).XTenant(meta.tenant).Patch(patchFromResourceData(d, "description")).Execute()
-		if err != nil {
+	// The workspace restarts to apply a new compute or image, then it is started or stopped, all within the update timeout
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutUpdate))
+	if d.HasChanges("instance_type", "version", "volume") {
+		if _, err := waitWorkspaceStatus(ctx, meta, d.Id(), status, workspaceDesiredStates, time.Until(deadline)); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
+	// The desired state only changes when it is configured, as it is computed otherwise
+	if d.HasChange("desired_state") {
+		if diagnostics := applyWorkspaceDesiredState(ctx, meta, d, deadline); diagnostics != nil {
+			return diagnostics
+		}
+	}
+
 	return resourceGraalSystemsWorkspaceRead(ctx, d, meta)
 }
 
 // resourceGraalSystemsWorkspaceDelete deletes a workspace
-func resourceGraalSystemsWorkspaceDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceGraalSystemsWorkspaceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	meta := m.(*Meta)
 	apiClient := meta.apiClient
 
 	_, err := apiClient.WorkspaceAPI.DeleteWorkspaceById(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
-	if err != nil && !is404Error(err) {
+	if err != nil {
+		if is404Error(err) {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	if _, err := waitWorkspaceStatus(ctx, meta, d.Id(), d.Get("status").(string), []string{workspaceStatusDeleted}, d.Timeout(schema.TimeoutDelete)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return nil
 }
+
+// readWorkspaceDesiredState returns the desired state to keep in the state for the status read from the API.
+// A workspace started or stopped outside of terraform is a drift of its desired state, except a running workspace
+// stopped by its idle timeout: starting it again on every apply would defeat the idle timeout.
+func readWorkspaceDesiredState(current string, status string, idleTimeoutMinutes int) string {
+	if !slices.Contains(workspaceDesiredStates, status) {
+		return current
+	}
+	if current == workspaceStatusRunning && status == workspaceStatusStopped && idleTimeoutMinutes > 0 {
+		return current
+	}
+	return status
+}
+
+// applyWorkspaceDesiredState waits for the workspace to settle, starts or stops it to reach its desired state, then waits for it.
+// The waits share the deadline of the whole operation, so that it never lasts longer than the timeout of the resource.
+func applyWorkspaceDesiredState(ctx context.Context, meta *Meta, d *schema.ResourceData, deadline time.Time) diag.Diagnostics {
+	apiClient := meta.apiClient
+
+	desiredState := d.Get("desired_state").(string)
+	workspace, err := waitWorkspaceStatus(ctx, meta, d.Id(), "", workspaceDesiredStates, time.Until(deadline))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if flattenStringPtr(workspace.Status) == desiredState {
+		return nil
+	}
+
+	if desiredState == workspaceStatusRunning {
+		_, _, err = apiClient.WorkspaceAPI.StartWorkspace(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	} else {
+		_, _, err = apiClient.WorkspaceAPI.StopWorkspace(context.Background(), d.Id()).XTenant(meta.tenant).Execute()
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if _, err := waitWorkspaceStatus(ctx, meta, d.Id(), flattenStringPtr(workspace.Status), []string{desiredState}, time.Until(deadline)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// waitWorkspaceStatus polls the workspace until it reaches one of the target statuses.
+// The previous status is the one of the workspace before the action waited for, if any: the API may still return it
+// right after the action, so it is only reported once the workspace left it, or after workspaceStatusChangeGracePeriod.
+// A failed workspace, or a workspace settling in another stable status, is reported with the status message of the server.
+func waitWorkspaceStatus(ctx context.Context, meta *Meta, workspaceId string, previous string, target []string, timeout time.Duration) (*sdk.Workspace, error) {
+	apiClient := meta.apiClient
+
+	find := func() (*sdk.Workspace, error) {
+		workspace, _, err := apiClient.WorkspaceAPI.FindWorkspaceById(context.Background(), workspaceId).XTenant(meta.tenant).Execute()
+		return workspace, err
+	}
+	stateConf := &resource.StateChangeConf{
+		Pending:    append([]string{workspaceStatusUnchanged}, workspaceTransitionalStatuses...),
+		Target:     target,
+		Timeout:    timeout,
+		Delay:      time.Second,
+		MinTimeout: workspacePollInterval,
+		Refresh:    workspaceStatusRefreshFunc(workspaceId, find, previous, time.Now().Add(workspaceStatusChangeGracePeriod), target),
+	}
+
+	result, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*sdk.Workspace), nil
+}
+
+// workspaceStatusRefreshFunc returns the status of the workspace read with find.
+// The previous status is reported as unchanged until the workspace leaves it, or until the grace period ends.
+func workspaceStatusRefreshFunc(workspaceId string, find func() (*sdk.Workspace, error), previous string, graceUntil time.Time, target []string) resource.StateRefreshFunc {
+	left := previous == ""
+	return func() (interface{}, string, error) {
+		workspace, err := find()
+		if err != nil {
+			if is404Error(err) {
+				return &sdk.Workspace{}, workspaceStatusDeleted, nil
+			}
+			return nil, "", err
+		}
+		status := flattenStringPtr(workspace.Status)
+		if !left {
+			if status == previous && time.Now().Before(graceUntil) {
+				return workspace, workspaceStatusUnchanged, nil
+			}
+			left = true
+		}
+		if !slices.Contains(target, status) && !slices.Contains(workspaceTransitionalStatuses, status) {
+			return nil, "", workspaceStatusError(workspaceId, workspace, target)
+		}
+		return workspace, status, nil
+	}
+}
+
+// workspaceStatusError describes a workspace that cannot reach the target statuses anymore
+func workspaceStatusError(workspaceId string, workspace *sdk.Workspace, target []string) error {
+	status := flattenStringPtr(workspace.Status)
+	message := flattenStringPtr(workspace.StatusMessage)
+	if message == "" {
+		message = "no status message"
+	}
+	if status == workspaceStatusFailed {
+		return fmt.Errorf("workspace %s failed: %s", workspaceId, message)
+	}
+	return fmt.Errorf("workspace %s is %s instead of %q: %s", workspaceId, status, target, message)
+}
+
+// defineWorkspaceVolumes converts the volume blocks to the volumes of the API
+func defineWorkspaceVolumes(input []interface{}) []sdk.WorkspaceVolume {
+	var volumes []sdk.WorkspaceVolume
+	for _, volume := range input {
+		v := volume.(map[string]interface{})
+		volumes = append(volumes, sdk.WorkspaceVolume{
+			Name:      expandStringPtr(v["name"]),
+			MountPath: expandStringPtr(v["mount_path"]),
+			SizeGb:    expandInt32Ptr(v["size_gb"]),
+		})
+	}
+	return volumes
+}
+
+// readWorkspaceVolumes converts the volumes returned by the API to the volume blocks
+func readWorkspaceVolumes(volumes []sdk.WorkspaceVolume) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, volume := range volumes {
+		result = append(result, map[string]interface{}{
+			"name":       flattenStringPtr(volume.Name),
+			"mount_path": flattenStringPtr(volume.MountPath),
+			"size_gb":    flattenInt32Ptr(volume.SizeGb),
+		})
+	}
+	return result
+}
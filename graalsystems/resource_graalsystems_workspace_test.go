@@ -0,0 +1,135 @@
+package graalsystems
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/graalsystems/sdk/go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceStatusError(t *testing.T) {
+	failed, stopped, message := workspaceStatusFailed, workspaceStatusStopped, "instance type unavailable in eu-west-1"
+
+	err := workspaceStatusError("ws", &sdk.Workspace{Status: &failed, StatusMessage: &message}, workspaceDesiredStates)
+	assert.EqualError(t, err, "workspace ws failed: instance type unavailable in eu-west-1")
+
+	err = workspaceStatusError("ws", &sdk.Workspace{Status: &stopped}, []string{workspaceStatusRunning})
+	assert.EqualError(t, err, `workspace ws is stopped instead of ["running"]: no status message`)
+}
+
+func TestDefineAndReadWorkspaceVolumes(t *testing.T) {
+	blocks := []interface{}{
+		map[string]interface{}{"name": "data", "mount_path": "/home/data", "size_gb": 50},
+	}
+
+	volumes := defineWorkspaceVolumes(blocks)
+	assert.Len(t, volumes, 1)
+	assert.Equal(t, int32(50), *volumes[0].SizeGb)
+
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "data", "mount_path": "/home/data", "size_gb": 50},
+	}, readWorkspaceVolumes(volumes))
+	assert.Nil(t, defineWorkspaceVolumes(nil))
+}
+
+func TestReadWorkspaceDesiredState(t *testing.T) {
+	// A workspace started or stopped outside of terraform is a drift
+	assert.Equal(t, workspaceStatusStopped, readWorkspaceDesiredState(workspaceStatusRunning, workspaceStatusStopped, 0))
+	assert.Equal(t, workspaceStatusRunning, readWorkspaceDesiredState(workspaceStatusStopped, workspaceStatusRunning, 60))
+	// A workspace stopped by its idle timeout is not
+	assert.Equal(t, workspaceStatusRunning, readWorkspaceDesiredState(workspaceStatusRunning, workspaceStatusStopped, 60))
+	// A transitional status keeps the current desired state
+	assert.Equal(t, workspaceStatusRunning, readWorkspaceDesiredState(workspaceStatusRunning, workspaceStatusStarting, 0))
+	// An imported workspace adopts its status
+	assert.Equal(t, workspaceStatusStopped, readWorkspaceDesiredState("", workspaceStatusStopped, 60))
+}
+
+func TestWorkspaceIdleStop_NoDiff(t *testing.T) {
+	workspaceSchema := resourceGraalSystemsWorkspace().Schema
+	config := map[string]interface{}{
+		"name": "ws", "type": "jupyter", "infrastructure_id": "infra", "instance_type": "Standard_D2s_v3", "idle_timeout_minutes": 60,
+	}
+	for _, desiredState := range []interface{}{nil, workspaceStatusRunning} {
+		if desiredState != nil {
+			config["desired_state"] = desiredState
+		}
+		// The workspace is read back once stopped by its idle timeout
+		d := schema.TestResourceDataRaw(t, workspaceSchema, config)
+		d.SetId("ws")
+		assert.Nil(t, d.Set("desired_state", readWorkspaceDesiredState(workspaceStatusRunning, workspaceStatusStopped, 60)))
+
+		diff, err := schema.InternalMap(workspaceSchema).Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), nil, nil, true)
+		assert.Nil(t, err)
+		if diff != nil {
+			assert.Nil(t, diff.Attributes["desired_state"], desiredState)
+		}
+	}
+}
+
+// fakeWorkspaceAPI returns a finder reading the workspace with each of the statuses in turn, the last one being kept
+func fakeWorkspaceAPI(statuses ...string) func() (*sdk.Workspace, error) {
+	return func() (*sdk.Workspace, error) {
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		return &sdk.Workspace{Status: &status}, nil
+	}
+}
+
+// refreshStatuses returns the statuses reported by the refresh function, until it fails
+func refreshStatuses(refresh resource.StateRefreshFunc, count int) ([]string, error) {
+	var statuses []string
+	for i := 0; i < count; i++ {
+		_, status, err := refresh()
+		if err != nil {
+			return statuses, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func TestWorkspaceStatusRefreshFunc(t *testing.T) {
+	graceUntil := time.Now().Add(time.Hour)
+
+	// The API still returns the status of a stopped workspace right after starting it
+	find := fakeWorkspaceAPI(workspaceStatusStopped, workspaceStatusStopped, workspaceStatusStarting, workspaceStatusRunning)
+	statuses, err := refreshStatuses(workspaceStatusRefreshFunc("ws", find, workspaceStatusStopped, graceUntil, []string{workspaceStatusRunning}), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{workspaceStatusUnchanged, workspaceStatusUnchanged, workspaceStatusStarting, workspaceStatusRunning}, statuses)
+
+	// A workspace restarting after an update is not ready before it left its status
+	find = fakeWorkspaceAPI(workspaceStatusRunning, workspaceStatusStopping, workspaceStatusStarting, workspaceStatusRunning)
+	statuses, err = refreshStatuses(workspaceStatusRefreshFunc("ws", find, workspaceStatusRunning, graceUntil, workspaceDesiredStates), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{workspaceStatusUnchanged, workspaceStatusStopping, workspaceStatusStarting, workspaceStatusRunning}, statuses)
+
+	// The API still returns the status of a deleted workspace right after deleting it
+	find = fakeWorkspaceAPI(workspaceStatusRunning, workspaceStatusDeleting)
+	statuses, err = refreshStatuses(workspaceStatusRefreshFunc("ws", find, workspaceStatusRunning, graceUntil, []string{workspaceStatusDeleted}), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{workspaceStatusUnchanged, workspaceStatusDeleting}, statuses)
+
+	// A workspace going back to its previous status once it left it fails
+	find = fakeWorkspaceAPI(workspaceStatusStarting, workspaceStatusStopped)
+	statuses, err = refreshStatuses(workspaceStatusRefreshFunc("ws", find, workspaceStatusStopped, graceUntil, []string{workspaceStatusRunning}), 2)
+	assert.EqualError(t, err, `workspace ws is stopped instead of ["running"]: no status message`)
+	assert.Equal(t, []string{workspaceStatusStarting}, statuses)
+
+	// A workspace keeping its previous status after the grace period fails
+	find = fakeWorkspaceAPI(workspaceStatusStopped)
+	_, err = refreshStatuses(workspaceStatusRefreshFunc("ws", find, workspaceStatusStopped, time.Now(), []string{workspaceStatusRunning}), 1)
+	assert.EqualError(t, err, `workspace ws is stopped instead of ["running"]: no status message`)
+
+	// Without previous status, the first status read is reported as is
+	find = fakeWorkspaceAPI(workspaceStatusStopped)
+	statuses, err = refreshStatuses(workspaceStatusRefreshFunc("ws", find, "", graceUntil, workspaceDesiredStates), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{workspaceStatusStopped}, statuses)
+}
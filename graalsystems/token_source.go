@@ -0,0 +1,148 @@
+package graalsystems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// credentialsTokenSource provides the tokens of a user in the credentials auth mode.
+// An expired access token is refreshed with the refresh token of the realm, and the user authenticates again
+// with its password once the refresh token expired too, e.g. when an apply waits longer than the SSO session.
+// A single token source is shared by all the resources, through the HTTP client of the API client.
+type credentialsTokenSource struct {
+	// ctx is only used to retrieve the HTTP client of the token requests. It must outlive the provider configuration.
+	ctx      context.Context
+	cfg      oauth2.Config
+	username string
+	password string
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// newCredentialsTokenSource creates a token source for the user, authenticating it immediately so that
+// wrong credentials are reported when configuring the provider
+func newCredentialsTokenSource(ctx context.Context, cfg oauth2.Config, username string, password string) (*credentialsTokenSource, error) {
+	source := &credentialsTokenSource{
+		ctx:      context.WithoutCancel(ctx),
+		cfg:      cfg,
+		username: username,
+		password: password,
+	}
+	token, err := source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid() {
+		return nil, fmt.Errorf("token invalid. Got: %#v", token)
+	}
+	return source, nil
+}
+
+// Token returns the current access token, refreshing it or authenticating again if it expired
+func (s *credentialsTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	if s.token != nil && s.token.RefreshToken != "" {
+		token, err := s.cfg.TokenSource(s.ctx, s.token).Token()
+		if err == nil {
+			l.Debugf("refreshed the access token of the user %s", s.username)
+			s.token = token
+			return token, nil
+		}
+		l.Debugf("cannot refresh the access token of the user %s, authenticating again: %s", s.username, err)
+	}
+
+	token, err := s.cfg.PasswordCredentialsToken(s.ctx, s.username, s.password)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// tokenResponse is the response of a token endpoint, as defined in RFC 6749
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// tokenError is an error returned by a token endpoint, e.g. authorization_pending while polling a device code
+type tokenError struct {
+	Code        string
+	Description string
+}
+
+func (e *tokenError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// requestToken posts a token request to the token endpoint, for the grants that are not supported by the oauth2 package
+func requestToken(ctx context.Context, tokenUrl string, form url.Values) (*oauth2.Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := contextHTTPClient(ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var response tokenResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("cannot parse the response of the token endpoint %s (HTTP %d): %s", tokenUrl, res.StatusCode, err)
+	}
+	if response.Error != "" {
+		return nil, &tokenError{Code: response.Error, Description: response.ErrorDescription}
+	}
+	if res.StatusCode != http.StatusOK || response.AccessToken == "" {
+		return nil, fmt.Errorf("the token endpoint %s returned no access token (HTTP %d)", tokenUrl, res.StatusCode)
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  response.AccessToken,
+		TokenType:    response.TokenType,
+		RefreshToken: response.RefreshToken,
+	}
+	if response.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// contextHTTPClient returns the HTTP client of the context, as the oauth2 package does for its own requests
+func contextHTTPClient(ctx context.Context) *http.Client {
+	if client, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && client != nil {
+		return client
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,247 @@
+package graalsystems
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// deviceCodeGrantType is the grant of the OAuth 2.0 device authorization flow, as defined in RFC 8628
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// deviceCodeDefaultLifetime is the lifetime of a device code whose expiration is not sent by the server
+const deviceCodeDefaultLifetime = 10 * time.Minute
+
+// deviceAuthorization is the response of the device authorization endpoint
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// deviceTokenSource provides the tokens of a user logging in with the OIDC device flow, e.g. in a browser of another device.
+// The tokens are cached on disk, so that the user only logs in again once the refresh token expired.
+type deviceTokenSource struct {
+	// ctx is only used to retrieve the HTTP client of the token requests. It must outlive the provider configuration.
+	ctx       context.Context
+	cfg       oauth2.Config
+	deviceUrl string
+	cachePath string
+	// prompt shows the user where to log in
+	prompt func(authorization *deviceAuthorization)
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// newDeviceTokenSource creates a token source for the realm, loading the cached token if any.
+// The user logs in immediately if no valid token can be obtained from the cache, so that the provider configuration waits for the login.
+func newDeviceTokenSource(ctx context.Context, cfg oauth2.Config, deviceUrl string, cachePath string) (*deviceTokenSource, error) {
+	source := &deviceTokenSource{
+		ctx:       context.WithoutCancel(ctx),
+		cfg:       cfg,
+		deviceUrl: deviceUrl,
+		cachePath: cachePath,
+		prompt:    promptDeviceAuthorization,
+		token:     loadCachedToken(cachePath),
+	}
+	if _, err := source.Token(); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// Token returns the current access token, refreshing it or asking the user to log in again if it expired
+func (s *deviceTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	if s.token != nil && s.token.RefreshToken != "" {
+		token, err := s.cfg.TokenSource(s.ctx, s.token).Token()
+		if err == nil {
+			s.setToken(token)
+			return token, nil
+		}
+		l.Debugf("cannot refresh the cached access token, logging in again: %s", err)
+	}
+
+	token, err := s.login()
+	if err != nil {
+		return nil, err
+	}
+	s.setToken(token)
+	return token, nil
+}
+
+// setToken keeps the token, and caches it on disk
+func (s *deviceTokenSource) setToken(token *oauth2.Token) {
+	s.token = token
+	if err := saveCachedToken(s.cachePath, token); err != nil {
+		l.Warningf("cannot cache the access token in %s: %s", s.cachePath, err)
+	}
+}
+
+// login runs the device flow: it requests a device code, shows the user where to log in, and polls the token endpoint until the user logged in
+func (s *deviceTokenSource) login() (*oauth2.Token, error) {
+	authorization, err := s.authorize()
+	if err != nil {
+		return nil, fmt.Errorf("cannot start the device login: %s", err)
+	}
+	s.prompt(authorization)
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	lifetime := time.Duration(authorization.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = deviceCodeDefaultLifetime
+	}
+	deadline := time.Now().Add(lifetime)
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {authorization.DeviceCode},
+		"client_id":   {s.cfg.ClientID},
+	}
+	for {
+		time.Sleep(interval)
+		token, err := requestToken(s.ctx, s.cfg.Endpoint.TokenURL, form)
+		var tokenErr *tokenError
+		if errors.As(err, &tokenErr) && tokenErr.Code == "authorization_pending" && time.Now().Before(deadline) {
+			continue
+		}
+		if errors.As(err, &tokenErr) && tokenErr.Code == "slow_down" && time.Now().Before(deadline) {
+			interval += 5 * time.Second
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("device login failed: %s", err)
+		}
+		return token, nil
+	}
+}
+
+// authorize requests a device code to the device authorization endpoint of the realm
+func (s *deviceTokenSource) authorize() (*deviceAuthorization, error) {
+	form := url.Values{
+		"client_id": {s.cfg.ClientID},
+		"scope":     {strings.Join(s.cfg.Scopes, " ")},
+	}
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.deviceUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := contextHTTPClient(s.ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the device authorization endpoint %s returned HTTP %d: %s", s.deviceUrl, res.StatusCode, body)
+	}
+	var authorization deviceAuthorization
+	if err := json.Unmarshal(body, &authorization); err != nil {
+		return nil, fmt.Errorf("cannot parse the response of the device authorization endpoint %s: %s", s.deviceUrl, err)
+	}
+	return &authorization, nil
+}
+
+// promptDeviceAuthorization shows the user where to log in.
+// Terraform does not display the output of the providers, so the prompt is written to the terminal when there is one, and logged in any case.
+func promptDeviceAuthorization(authorization *deviceAuthorization) {
+	message := fmt.Sprintf("To authenticate to GraalSystems, open %s and enter the code %s", authorization.VerificationUri, authorization.UserCode)
+	if authorization.VerificationUriComplete != "" {
+		message = fmt.Sprintf("To authenticate to GraalSystems, open %s and check that the code is %s", authorization.VerificationUriComplete, authorization.UserCode)
+	}
+	l.Warningf("%s", message)
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		_, _ = fmt.Fprintln(tty, message)
+		_ = tty.Close()
+	}
+}
+
+// deviceTokenCachePath returns the path of the token cache of a client of a realm, in ~/.graalsystems/tokens
+func deviceTokenCachePath(tokenUrl string, clientId string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(tokenUrl + " " + clientId))
+	return filepath.Join(home, ".graalsystems", "tokens", hex.EncodeToString(hash[:8])+".json")
+}
+
+// loadCachedToken returns the cached token, or nil if there is none
+func loadCachedToken(path string) *oauth2.Token {
+	if path == "" {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(content, &token); err != nil {
+		l.Debugf("ignoring the invalid token cache %s: %s", path, err)
+		return nil
+	}
+	return &token
+}
+
+// saveCachedToken caches the token in a file only readable by the user.
+// The token is written to a new file renamed over the cache, so that an existing cache readable by others is replaced.
+func saveCachedToken(path string, token *oauth2.Token) error {
+	if path == "" {
+		return nil
+	}
+	content, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if err := file.Chmod(0600); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
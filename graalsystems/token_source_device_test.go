@@ -0,0 +1,107 @@
+package graalsystems
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestDeviceTokenSource(t *testing.T) {
+	// The device code of a server not sending its expiration gets the default lifetime
+	for _, expiresIn := range []int64{60, 0} {
+		testDeviceTokenSource(t, expiresIn)
+	}
+}
+
+func testDeviceTokenSource(t *testing.T, expiresIn int64) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/device", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assert.Equal(t, "graal-cli", r.PostForm.Get("client_id"))
+		_ = json.NewEncoder(w).Encode(deviceAuthorization{
+			DeviceCode:      "device-code",
+			UserCode:        "ABCD-EFGH",
+			VerificationUri: "https://identity.graal.systems/device",
+			ExpiresIn:       expiresIn,
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assert.Equal(t, deviceCodeGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "device-code", r.PostForm.Get("device_code"))
+		polls++
+		if polls == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    300,
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "tokens", "token.json")
+	var prompted *deviceAuthorization
+	source := &deviceTokenSource{
+		ctx:       context.Background(),
+		cfg:       oauth2.Config{ClientID: "graal-cli", Endpoint: oauth2.Endpoint{TokenURL: server.URL + "/token"}},
+		deviceUrl: deviceAuthorizationUrl(server.URL + "/token"),
+		cachePath: cachePath,
+		prompt:    func(authorization *deviceAuthorization) { prompted = authorization },
+	}
+
+	token, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, "ABCD-EFGH", prompted.UserCode)
+	assert.Equal(t, 2, polls)
+
+	// The token is cached, so that the next run does not need to log in again
+	cached := loadCachedToken(cachePath)
+	if assert.NotNil(t, cached) {
+		assert.Equal(t, "refresh", cached.RefreshToken)
+		assert.True(t, cached.Valid())
+	}
+}
+
+func TestDeviceTokenCachePath(t *testing.T) {
+	path := deviceTokenCachePath("https://identity.graal.systems/realms/acme/protocol/openid-connect/token", "graal-ui")
+	assert.Equal(t, "tokens", filepath.Base(filepath.Dir(path)))
+	assert.NotEqual(t, path, deviceTokenCachePath("https://identity.graal.systems/realms/other/protocol/openid-connect/token", "graal-ui"))
+	assert.Equal(t, "https://identity.graal.systems/realms/acme/protocol/openid-connect/auth/device",
+		deviceAuthorizationUrl("https://identity.graal.systems/realms/acme/protocol/openid-connect/token"))
+}
+
+func TestSaveCachedToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens", "token.json")
+	assert.NoError(t, saveCachedToken(path, &oauth2.Token{AccessToken: "access"}))
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	// A cache readable by others is replaced by a file only readable by the user
+	assert.NoError(t, os.Chmod(path, 0644))
+	assert.NoError(t, saveCachedToken(path, &oauth2.Token{AccessToken: "renewed"}))
+	info, err = os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	assert.Equal(t, "renewed", loadCachedToken(path).AccessToken)
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,133 @@
+package graalsystems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// jwtBearerGrantType is the grant exchanging a JWT issued by a trusted identity provider for an access token, as defined in RFC 7523
+const jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// federatedTokenSource exchanges an OIDC token of a CI platform, e.g. GitHub Actions or GitLab CI, for a GraalSystems access token.
+// The CI token is read again for every exchange, since CI tokens are short-lived and may be rotated during the job.
+type federatedTokenSource struct {
+	// ctx is only used to retrieve the HTTP client of the token requests. It must outlive the provider configuration.
+	ctx      context.Context
+	tokenUrl string
+	clientId string
+	// assertion returns the OIDC token of the CI platform
+	assertion func(ctx context.Context) (string, error)
+}
+
+// newFederatedTokenSource creates a token source exchanging the CI token, exchanging it immediately so that
+// an untrusted token is reported when configuring the provider. The access token is reused until it expires.
+func newFederatedTokenSource(ctx context.Context, tokenUrl string, clientId string, assertion func(ctx context.Context) (string, error)) (oauth2.TokenSource, error) {
+	source := &federatedTokenSource{
+		ctx:       context.WithoutCancel(ctx),
+		tokenUrl:  tokenUrl,
+		clientId:  clientId,
+		assertion: assertion,
+	}
+	token, err := source.Token()
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSource(token, source), nil
+}
+
+// Token exchanges the current CI token for an access token
+func (s *federatedTokenSource) Token() (*oauth2.Token, error) {
+	assertion, err := s.assertion(s.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the OIDC token of the CI platform: %s", err)
+	}
+	token, err := requestToken(s.ctx, s.tokenUrl, url.Values{
+		"grant_type": {jwtBearerGrantType},
+		"assertion":  {assertion},
+		"client_id":  {s.clientId},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot exchange the OIDC token of the CI platform: %s", err)
+	}
+	return token, nil
+}
+
+// federatedAssertion returns the function providing the OIDC token of the CI platform, in this priority order:
+//  1. the oidc_token argument, e.g. an ID token of GitLab CI
+//  2. the file of the oidc_token_file argument, e.g. a projected service account token of Kubernetes
+//  3. the ID token of GitHub Actions, requested with the client id as audience. The job needs the id-token: write permission.
+func federatedAssertion(oidcToken string, oidcTokenFile string, audience string) (func(ctx context.Context) (string, error), error) {
+	if oidcToken != "" {
+		return func(context.Context) (string, error) { return oidcToken, nil }, nil
+	}
+	if oidcTokenFile != "" {
+		return func(context.Context) (string, error) {
+			content, err := os.ReadFile(oidcTokenFile)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(content)), nil
+		}, nil
+	}
+	if requestUrl, requestBearer, ok := gitHubActionsTokenRequest(); ok {
+		return func(ctx context.Context) (string, error) {
+			return requestGitHubActionsToken(ctx, requestUrl, requestBearer, audience)
+		}, nil
+	}
+	return nil, fmt.Errorf("no OIDC token found: set oidc_token or oidc_token_file, or run in GitHub Actions with the id-token: write permission")
+}
+
+// gitHubActionsTokenRequest returns the URL and the bearer token to request an ID token of the running GitHub Actions job.
+// It returns false outside of GitHub Actions, or if the job lacks the id-token: write permission.
+func gitHubActionsTokenRequest() (string, string, bool) {
+	requestUrl := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestBearer := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	return requestUrl, requestBearer, requestUrl != "" && requestBearer != ""
+}
+
+// requestGitHubActionsToken requests an ID token of the running GitHub Actions job
+func requestGitHubActionsToken(ctx context.Context, requestUrl string, requestBearer string, audience string) (string, error) {
+	u, err := url.Parse(requestUrl)
+	if err != nil {
+		return "", err
+	}
+	if audience != "" {
+		query := u.Query()
+		query.Set("audience", audience)
+		u.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestBearer)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := contextHTTPClient(ctx).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub Actions returned HTTP %d when requesting the ID token", res.StatusCode)
+	}
+	var response struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.Value == "" {
+		return "", fmt.Errorf("GitHub Actions returned no ID token")
+	}
+	return response.Value, nil
+}
@@ -0,0 +1,70 @@
+package graalsystems
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFederatedTokenSource(t *testing.T) {
+	var assertions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assert.Equal(t, jwtBearerGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "graal-ci", r.PostForm.Get("client_id"))
+		assertions = append(assertions, r.PostForm.Get("assertion"))
+		if r.PostForm.Get("assertion") == "untrusted" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "Invalid token issuer"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 300})
+	}))
+	defer server.Close()
+
+	// The token file is read for every exchange
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("ci-token\n"), 0600))
+	assertion, err := federatedAssertion("", tokenFile, "graal-ci")
+	assert.NoError(t, err)
+
+	source, err := newFederatedTokenSource(context.Background(), server.URL, "graal-ci", assertion)
+	assert.NoError(t, err)
+	token, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access", token.AccessToken)
+	// The access token is reused until it expires
+	assert.Equal(t, []string{"ci-token"}, assertions)
+
+	assertion, err = federatedAssertion("untrusted", "", "graal-ci")
+	assert.NoError(t, err)
+	_, err = newFederatedTokenSource(context.Background(), server.URL, "graal-ci", assertion)
+	assert.ErrorContains(t, err, "Invalid token issuer")
+}
+
+func TestFederatedAssertionGitHubActions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer request-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "graal-ci", r.URL.Query().Get("audience"))
+		_ = json.NewEncoder(w).Encode(map[string]string{"value": "github-token"})
+	}))
+	defer server.Close()
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	_, err := federatedAssertion("", "", "graal-ci")
+	assert.Error(t, err)
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+	assertion, err := federatedAssertion("", "", "graal-ci")
+	assert.NoError(t, err)
+	value, err := assertion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "github-token", value)
+}
@@ -0,0 +1,68 @@
+package graalsystems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestCredentialsTokenSource(t *testing.T) {
+	var grants []string
+	refreshable := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		grantType := r.PostForm.Get("grant_type")
+		grants = append(grants, grantType)
+		if grantType == "refresh_token" && !refreshable {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant", "error_description": "Session not active"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		// The test expires the tokens by moving their expiry back
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access-%d", len(grants)),
+			"refresh_token": fmt.Sprintf("refresh-%d", len(grants)),
+			"token_type":    "Bearer",
+			"expires_in":    30,
+		})
+	}))
+	defer server.Close()
+
+	cfg := oauth2.Config{ClientID: "graal-ui", Endpoint: oauth2.Endpoint{TokenURL: server.URL}}
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &credentialsTokenSource{ctx: context.WithoutCancel(ctx), cfg: cfg, username: "jane", password: "secret"}
+	// The configuration context is cancelled once the provider is configured
+	cancel()
+
+	token, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+
+	// The expired token is refreshed
+	token.Expiry = token.Expiry.Add(-30 * time.Second)
+	token, err = source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+
+	// The user authenticates again once the refresh token expired
+	refreshable = false
+	token.Expiry = token.Expiry.Add(-30 * time.Second)
+	token, err = source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-4", token.AccessToken)
+	assert.Equal(t, []string{"password", "refresh_token", "refresh_token", "password"}, grants)
+
+	// A valid token is reused
+	token, err = source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-4", token.AccessToken)
+	assert.Len(t, grants, 4)
+}
@@ -0,0 +1,247 @@
+package graalsystems
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries            = 5
+	defaultMaxConcurrentRequests = 10
+	defaultRequestTimeoutSeconds = 60
+)
+
+const (
+	retryMinBackoff = 1 * time.Second
+	retryMaxBackoff = 30 * time.Second
+	// retryMaxRetryAfter is the longest Retry-After honored. The response is returned as is when the API asks to wait longer.
+	retryMaxRetryAfter = 2 * time.Minute
+)
+
+// transportConfig configures the retries, the rate limiting and the timeouts of the requests
+type transportConfig struct {
+	// maxRetries is the number of retries of a request, after its first attempt
+	maxRetries int
+	// maxConcurrentRequests is the number of requests sent at the same time, 0 for no limit
+	maxConcurrentRequests int
+	// requestsPerSecond is the number of requests sent per second, 0 for no limit
+	requestsPerSecond float64
+	// requestTimeout is the timeout of each attempt of a request, 0 for no timeout
+	requestTimeout time.Duration
+	// minBackoff and maxBackoff bound the exponential backoff between the attempts
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// retryTransport retries the requests failing with a transient error, with an exponential backoff honoring the Retry-After header.
+// It also limits the number of concurrent requests and their rate, shared by all the resources, and times out each attempt.
+type retryTransport struct {
+	base      http.RoundTripper
+	config    transportConfig
+	semaphore chan struct{}
+	limiter   *rateLimiter
+}
+
+// newRetryTransport creates a transport sending the requests with the base transport
+func newRetryTransport(base http.RoundTripper, config transportConfig) *retryTransport {
+	if config.minBackoff <= 0 {
+		config.minBackoff = retryMinBackoff
+	}
+	if config.maxBackoff <= 0 {
+		config.maxBackoff = retryMaxBackoff
+	}
+	transport := &retryTransport{base: base, config: config}
+	if config.maxConcurrentRequests > 0 {
+		transport.semaphore = make(chan struct{}, config.maxConcurrentRequests)
+	}
+	if config.requestsPerSecond > 0 {
+		transport.limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / config.requestsPerSecond)}
+	}
+	return transport
+}
+
+// RoundTrip sends the request, retrying it while the error is transient and the retries are not exhausted
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, errors.New("cannot retry a request whose body cannot be read again")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		res, err := t.send(req)
+		if attempt >= t.config.maxRetries || ctx.Err() != nil || !isRetryable(req, res, err) {
+			return res, err
+		}
+		wait, ok := t.backoff(attempt, res)
+		if !ok {
+			return res, err
+		}
+		if res != nil {
+			// The body is drained, so that the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+			_ = res.Body.Close()
+			l.Debugf("retrying %s %s in %s after HTTP %d (attempt %d/%d)", req.Method, req.URL.Path, wait, res.StatusCode, attempt+1, t.config.maxRetries)
+		} else {
+			l.Debugf("retrying %s %s in %s after %s (attempt %d/%d)", req.Method, req.URL.Path, wait, err, attempt+1, t.config.maxRetries)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends a single attempt of the request, once the concurrency and the rate limits allow it
+func (t *retryTransport) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.semaphore != nil {
+		select {
+		case t.semaphore <- struct{}{}:
+			defer func() { <-t.semaphore }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err := t.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if t.config.requestTimeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, t.config.requestTimeout)
+	res, err := t.base.RoundTrip(req.WithContext(attemptCtx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout also covers the read of the body, so it is only released once the body is closed
+	res.Body = &cancelOnCloseBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// backoff returns the time to wait before the next attempt: the Retry-After delay if the API sent one, an exponential backoff with jitter otherwise.
+// It returns false if the API asks to wait longer than retryMaxRetryAfter.
+func (t *retryTransport) backoff(attempt int, res *http.Response) (time.Duration, bool) {
+	if res != nil {
+		if wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return wait, wait <= retryMaxRetryAfter
+		}
+	}
+	wait := t.config.minBackoff << attempt
+	if wait <= 0 || wait > t.config.maxBackoff {
+		wait = t.config.maxBackoff
+	}
+	// Half of the backoff is random, so that the concurrent requests do not retry at the same time
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)), true
+}
+
+// isRetryable returns true if the request failed with a transient error.
+// Rate limited and unavailable responses are always retried, since the API did not process the request.
+// Network errors, timeouts and gateway errors are only retried for the idempotent methods, since the API may have processed the request.
+func isRetryable(req *http.Request, res *http.Response, err error) bool {
+	if err == nil {
+		switch res.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return true
+		case http.StatusBadGateway, http.StatusGatewayTimeout:
+			return isIdempotent(req.Method)
+		}
+		return false
+	}
+	return isIdempotent(req.Method)
+}
+
+// isIdempotent returns true if sending the request several times has the same effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses the Retry-After header, either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the duration, or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cancelOnCloseBody releases the timeout of an attempt once its body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// rateLimiter spaces the requests evenly, to send at most one request per interval
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait waits for the next slot of the rate limiter. A nil rate limiter does not limit the rate.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	now := time.Now()
+	slot := r.next
+	if slot.Before(now) {
+		slot = now
+	}
+	r.next = slot.Add(r.interval)
+	r.mu.Unlock()
+
+	if delay := slot.Sub(now); delay > 0 {
+		return sleepContext(ctx, delay)
+	}
+	return nil
+}
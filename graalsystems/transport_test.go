@@ -0,0 +1,203 @@
+package graalsystems
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRetryClient(config transportConfig) *http.Client {
+	config.minBackoff = time.Millisecond
+	config.maxBackoff = 5 * time.Millisecond
+	return &http.Client{Transport: newRetryTransport(http.DefaultTransport, config)}
+}
+
+func TestRetryTransport_Retries(t *testing.T) {
+	var calls int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	// The body of the request is sent again with each attempt
+	res, err := newTestRetryClient(transportConfig{maxRetries: 3}).Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+}
+
+func TestRetryTransport_RetryBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	res, err := newTestRetryClient(transportConfig{maxRetries: 2}).Get(server.URL)
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// No retry at all with a budget of 0
+	atomic.StoreInt32(&calls, 0)
+	res, err = newTestRetryClient(transportConfig{}).Get(server.URL)
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryTransport_NotRetried(t *testing.T) {
+	var calls int32
+	status := http.StatusBadGateway
+	retryAfter := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	client := newTestRetryClient(transportConfig{maxRetries: 3})
+
+	// A gateway error is not retried for a POST, since the API may have created the object
+	res, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// A client error is not retried
+	atomic.StoreInt32(&calls, 0)
+	status = http.StatusNotFound
+	res, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// The response is returned when the API asks to wait too long
+	atomic.StoreInt32(&calls, 0)
+	status = http.StatusTooManyRequests
+	retryAfter = "3600"
+	res, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryTransport_RequestTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// The attempt timing out is retried, and the timeout does not interrupt the read of the next response
+	res, err := newTestRetryClient(transportConfig{maxRetries: 1, requestTimeout: 50 * time.Millisecond}).Get(server.URL)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	_, err = newTestRetryClient(transportConfig{requestTimeout: 50 * time.Millisecond}).Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestRetryTransport_MaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer server.Close()
+
+	client := newTestRetryClient(transportConfig{maxConcurrentRequests: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := client.Get(server.URL); err == nil {
+				_ = res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestRetryTransport_RequestsPerSecond(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := newTestRetryClient(transportConfig{requestsPerSecond: 20})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		res, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		_ = res.Body.Close()
+	}
+	// The first request is sent immediately, the next ones every 50ms
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, test := range tests {
+		wait, ok := parseRetryAfter(test.value, now)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.expected, wait, test.value)
+	}
+}
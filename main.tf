@@ -37,7 +37,9 @@ resource "graalsystems_job" "my_job" {
     "pipeline": "data-ingestion"
   }
 
-  spark {
+  options {
+    type = "spark"
+    docker_image = "docker.io/graalsystems/spark:3.3.0-release-1"
     instance_type = "Standard_General_G1_v1"
     main_class_name = "org.acme.MySparkJob"
   }